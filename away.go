@@ -0,0 +1,128 @@
+package tplink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const minutesPerDay = 24 * 60
+
+func validAwayRule(days Days, startMinutes int, endMinutes int, frequency int, duration int) error {
+	if days == (Days{}) {
+		return errors.New("at least one repeat day must be set")
+	}
+
+	if startMinutes < 0 || startMinutes >= minutesPerDay {
+		return fmt.Errorf("invalid start minute %d, must be between 0 and %d", startMinutes, minutesPerDay-1)
+	}
+
+	if endMinutes < 0 || endMinutes >= minutesPerDay {
+		return fmt.Errorf("invalid end minute %d, must be between 0 and %d", endMinutes, minutesPerDay-1)
+	}
+
+	if endMinutes == startMinutes {
+		return fmt.Errorf("invalid window %d-%d, end must differ from start", startMinutes, endMinutes)
+	}
+
+	// a window ending before it starts crosses midnight, e.g. 22:00 to 02:00
+	length := (endMinutes - startMinutes + minutesPerDay) % minutesPerDay
+
+	if frequency < 1 {
+		return fmt.Errorf("invalid frequency %d, must be at least 1", frequency)
+	}
+
+	if duration < 1 || frequency*duration > length {
+		return fmt.Errorf("invalid duration %d, %d times must fit in a %d minutes window", duration, frequency, length)
+	}
+	return nil
+}
+
+// Gets Away Mode Rules
+func (p *HS100) AwayModeRules() ([]AwayRule, error) {
+	return p.AwayModeRulesContext(context.Background())
+}
+
+func (p *HS100) AwayModeRulesContext(ctx context.Context) ([]AwayRule, error) {
+	r, err := p.do(ctx, getAwayRulesRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	return r.AntiTheft.Rule.List, nil
+}
+
+// Add Away Mode Rule repeating on days, turning the device on frequency times for duration minutes between startMinutes and endMinutes (minutes after midnight).
+// The window crosses midnight when endMinutes is before startMinutes.
+func (p *HS100) AddAwayModeRule(name string, days Days, startMinutes int, endMinutes int, frequency int, duration int, enable int) (string, error) {
+	return p.AddAwayModeRuleContext(context.Background(), name, days, startMinutes, endMinutes, frequency, duration, enable)
+}
+
+func (p *HS100) AddAwayModeRuleContext(ctx context.Context, name string, days Days, startMinutes int, endMinutes int, frequency int, duration int, enable int) (string, error) {
+	if err := validAwayRule(days, startMinutes, endMinutes, frequency, duration); err != nil {
+		return "", err
+	}
+
+	req := addAwayRuleRequest(newAwayRuleParams("", name, days, startMinutes, endMinutes, frequency, duration, enable))
+	r, err := p.do(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	return r.AntiTheft.AddRule.ID, nil
+}
+
+// Edit Away Mode Rule with given ID
+func (p *HS100) EditAwayModeRule(id string, name string, days Days, startMinutes int, endMinutes int, frequency int, duration int, enable int) error {
+	return p.EditAwayModeRuleContext(context.Background(), id, name, days, startMinutes, endMinutes, frequency, duration, enable)
+}
+
+func (p *HS100) EditAwayModeRuleContext(ctx context.Context, id string, name string, days Days, startMinutes int, endMinutes int, frequency int, duration int, enable int) error {
+	if err := validAwayRule(days, startMinutes, endMinutes, frequency, duration); err != nil {
+		return err
+	}
+
+	req := editAwayRuleRequest(newAwayRuleParams(id, name, days, startMinutes, endMinutes, frequency, duration, enable))
+	_, err := p.do(ctx, req)
+	return err
+}
+
+// Delete Away Mode Rule with given ID
+func (p *HS100) DeleteAwayModeRule(id string) error {
+	return p.DeleteAwayModeRuleContext(context.Background(), id)
+}
+
+func (p *HS100) DeleteAwayModeRuleContext(ctx context.Context, id string) error {
+	_, err := p.do(ctx, deleteAwayRuleRequest(id))
+	return err
+}
+
+// Delete All Away Mode Rules
+func (p *HS100) DeleteAllAwayModeRules() error {
+	return p.DeleteAllAwayModeRulesContext(context.Background())
+}
+
+func (p *HS100) DeleteAllAwayModeRulesContext(ctx context.Context) error {
+	_, err := p.do(ctx, deleteAllAwayRulesRequest())
+	return err
+}
+
+// Enable Away Mode
+func (p *HS100) EnableAwayMode() error {
+	return p.EnableAwayModeContext(context.Background())
+}
+
+func (p *HS100) EnableAwayModeContext(ctx context.Context) error {
+	_, err := p.do(ctx, setAwayModeEnableRequest(ENABLED))
+	return err
+}
+
+// Disable Away Mode
+func (p *HS100) DisableAwayMode() error {
+	return p.DisableAwayModeContext(context.Background())
+}
+
+func (p *HS100) DisableAwayModeContext(ctx context.Context) error {
+	_, err := p.do(ctx, setAwayModeEnableRequest(DISABLED))
+	return err
+}
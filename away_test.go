@@ -0,0 +1,65 @@
+package tplink
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAwayMode(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		`{"anti_theft":{"add_rule":{"stime_opt":0,"wday":[1,0,0,0,0,0,1],"smin":1080,"enable":1,"frequency":3,"repeat":1,"etime_opt":0,"duration":20,"name":"vacation","lastfor":1,"month":0,"year":0,"longitude":0,"day":0,"latitude":0,"force":0,"emin":1380},"set_overall_enable":{"enable":1}}}`: `{"anti_theft":{"add_rule":{"id":"E36B1F4466B135C1FD481F0B4BFC9C30","err_code":0},"set_overall_enable":{"err_code":0}}}`,
+		`{"anti_theft":{"get_rules":null}}`:                  `{"anti_theft":{"get_rules":{"rule_list":[{"id":"E36B1F4466B135C1FD481F0B4BFC9C30","name":"vacation","enable":1,"smin":1080,"emin":1380,"frequency":3,"duration":20,"repeat":1,"wday":[1,0,0,0,0,0,1]}],"enable":1,"err_code":0}}}`,
+		`{"anti_theft":{"set_overall_enable":{"enable":0}}}`: `{"anti_theft":{"set_overall_enable":{"err_code":0}}}`,
+	}}
+	p := NewHS100("10.0.0.1", time.Second, WithTransport(f))
+
+	weekend := Days{Sunday: true, Saturday: true}
+	id, err := p.AddAwayModeRule("vacation", weekend, 18*60, 23*60, 3, 20, ENABLED)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "E36B1F4466B135C1FD481F0B4BFC9C30" {
+		t.Errorf("unexpected id %q", id)
+	}
+
+	rules, err := p.AwayModeRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].ID != id || !rules[0].IsEnabled() || rules[0].StartMinutes != 1080 || rules[0].EndMinutes != 1380 || rules[0].Frequency != 3 {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+
+	if err := p.DisableAwayMode(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidAwayRule(t *testing.T) {
+	weekend := Days{Sunday: true, Saturday: true}
+	tt := []struct {
+		days                            Days
+		start, end, frequency, duration int
+		valid                           bool
+	}{
+		{Days{}, 1080, 1380, 1, 1, false},
+		{weekend, 0, 1439, 1, 1, true},
+		{weekend, 1080, 1380, 3, 100, true},
+		{weekend, -1, 60, 1, 1, false},
+		{weekend, 0, 1440, 1, 1, false},
+		{weekend, 600, 600, 1, 1, false},
+		{weekend, 600, 540, 1, 1, true},
+		{weekend, 22 * 60, 2 * 60, 4, 60, true},
+		{weekend, 22 * 60, 2 * 60, 5, 60, false},
+		{weekend, 600, 660, 0, 1, false},
+		{weekend, 600, 660, 1, 0, false},
+		{weekend, 600, 660, 2, 31, false},
+	}
+
+	for _, v := range tt {
+		err := validAwayRule(v.days, v.start, v.end, v.frequency, v.duration)
+		if (err == nil) != v.valid {
+			t.Errorf("%+v: unexpected error %v", v, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package tplink
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// Batch sends several commands, possibly to different modules, in a single round trip:
+//
+//	r, err := plug.Batch().Info().Meter().Time().NextAction().Do()
+//
+// Adding the same command twice keeps the last parameters.
+type Batch struct {
+	c   *client
+	req request
+}
+
+// BatchResult holds the decoded reply of a batch and the errors reported by the device, one per failed method
+type BatchResult struct {
+	Response
+	Errors []*DeviceError
+}
+
+// Err returns the error reported for module.method, or for the whole module, if any
+func (r *BatchResult) Err(module string, method string) error {
+	for _, e := range r.Errors {
+		if e.Module == module && (e.Method == method || e.Method == "") {
+			return e
+		}
+	}
+	return nil
+}
+
+// Start a new batch of commands
+func (c *client) Batch() *Batch {
+	return &Batch{c: c}
+}
+
+func (b *Batch) add(req *request) *Batch {
+	merge(&b.req, req)
+	return b
+}
+
+// System Info
+func (b *Batch) Info() *Batch {
+	return b.add(getInfoRequest())
+}
+
+// Realtime Current and Voltage Reading
+func (b *Batch) Meter() *Batch {
+	return b.add(getMeterRequest())
+}
+
+// Daily Statistic for given Month
+func (b *Batch) DailyStats(month int, year int) *Batch {
+	return b.add(getDailyStatsRequest(month, year))
+}
+
+// Monthly Statistic for given Year
+func (b *Batch) MonthlyStats(year int) *Batch {
+	return b.add(getMonthlyStatsRequest(year))
+}
+
+// Device Time
+func (b *Batch) Time() *Batch {
+	return b.add(getTimeRequest())
+}
+
+// Device Time Zone
+func (b *Batch) TimeZone() *Batch {
+	return b.add(getTimeZoneRequest())
+}
+
+// Next Scheduled Action
+func (b *Batch) NextAction() *Batch {
+	return b.add(getNextActionRequest())
+}
+
+// Schedule Rules List
+func (b *Batch) ScheduleRules() *Batch {
+	return b.add(getRulesRequest())
+}
+
+// Cloud Info
+func (b *Batch) CloudInfo() *Batch {
+	return b.add(getCloudInfoRequest())
+}
+
+// Send the batch
+func (b *Batch) Do() (*BatchResult, error) {
+	return b.DoContext(context.Background())
+}
+
+func (b *Batch) DoContext(ctx context.Context) (*BatchResult, error) {
+	data, err := b.c.send(ctx, &b.req)
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := deviceErrors(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &BatchResult{Errors: errs}
+	if err := json.Unmarshal([]byte(data), &r.Response); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// merge copies the modules and methods set in src into dst, modules being merged method by method
+func merge(dst *request, src *request) {
+	d := reflect.ValueOf(dst).Elem()
+	s := reflect.ValueOf(src).Elem()
+	for i := 0; i < s.NumField(); i++ {
+		module := s.Field(i)
+		if module.IsNil() {
+			continue
+		}
+
+		if d.Field(i).IsNil() {
+			d.Field(i).Set(reflect.New(module.Elem().Type()))
+		}
+
+		for j := 0; j < module.Elem().NumField(); j++ {
+			if method := module.Elem().Field(j); !method.IsNil() {
+				d.Field(i).Elem().Field(j).Set(method)
+			}
+		}
+	}
+}
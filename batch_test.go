@@ -0,0 +1,52 @@
+package tplink
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBatch(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		`{"system":{"get_sysinfo":{}},"time":{"get_time":{}},"schedule":{"get_next_action":null},"emeter":{"get_realtime":{}}}`: `{
+			"system":{"get_sysinfo":{"alias":"Plug1","err_code":0}},
+			"time":{"get_time":{"year":2018,"month":3,"mday":4,"hour":5,"min":6,"sec":7,"err_code":0}},
+			"schedule":{"get_next_action":{"err_code":-2,"err_msg":"method not support"}},
+			"emeter":{"get_realtime":{"power":12.5,"err_code":0}}
+		}`,
+	}}
+	plug := NewHS110("10.0.0.1", time.Second, WithTransport(f))
+
+	r, err := plug.Batch().Info().Meter().Time().NextAction().Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.sent) != 1 {
+		t.Errorf("expecting a single request; got %d", len(f.sent))
+	}
+
+	if r.System.Info.Alias != "Plug1" || r.Time.GetTime.Year != 2018 || r.EMeter.Meter.Power != 12.5 {
+		t.Errorf("unexpected response: %+v", r.Response)
+	}
+
+	if err := r.Err("schedule", "get_next_action"); !errors.Is(err, ErrMethodNotSupported) {
+		t.Errorf("expecting %v; got %v", ErrMethodNotSupported, err)
+	}
+
+	if err := r.Err("system", "get_sysinfo"); err != nil {
+		t.Errorf("expecting no error; got %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	req := getMeterRequest()
+	merge(req, getTimeRequest())
+	merge(req, setRelayStateRequest(ON))
+	merge(req, getDailyStatsRequest(3, 2018))
+
+	expecting := `{"system":{"set_relay_state":{"state":1}},"time":{"get_time":{}},"emeter":{"get_realtime":{},"get_daystat":{"month":3,"year":2018}}}`
+	if s := marshal(t, req); !sameJSON(t, s, expecting) {
+		t.Errorf("expecting %s; got %s", expecting, s)
+	}
+}
@@ -0,0 +1,296 @@
+package tplink
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Lighting state of a smart bulb
+type LightState struct {
+	OnOff          int         `json:"on_off"`                 // 0 = OFF; 1 = ON
+	Mode           string      `json:"mode"`                   // "normal" or "circadian"
+	Hue            int         `json:"hue"`                    // 0 to 360
+	Saturation     int         `json:"saturation"`             // 0 to 100
+	ColorTemp      int         `json:"color_temp"`             // Kelvin, 0 when a color is set
+	Brightness     int         `json:"brightness"`             // 0 to 100
+	DefaultOnState *LightState `json:"dft_on_state,omitempty"` // State restored on power on, only reported when the bulb is off
+}
+
+func (s LightState) IsOn() bool {
+	return s.OnOff == 1
+}
+
+// A preset saved on the bulb
+type PreferredState struct {
+	Index      int `json:"index"`
+	Hue        int `json:"hue"`
+	Saturation int `json:"saturation"`
+	ColorTemp  int `json:"color_temp"`
+	Brightness int `json:"brightness"`
+}
+
+// System information of a smart bulb
+type BulbInfo struct {
+	Info
+	Description         string           `json:"description"`
+	IsDimmable          int              `json:"is_dimmable"`
+	IsColor             int              `json:"is_color"`
+	IsVariableColorTemp int              `json:"is_variable_color_temp"`
+	LightState          LightState       `json:"light_state"`
+	PreferredState      []PreferredState `json:"preferred_state"`
+}
+
+// TP-Link smart bulb, KL and LB series.
+// Every state change uses Transition as transition period.
+type Bulb struct {
+	client
+	Transition time.Duration
+
+	lightStrip bool // light state commands go to the smartlife.iot.lightStrip module
+}
+
+type bulbInfoResponse struct {
+	System struct {
+		Info *BulbInfo `json:"get_sysinfo"`
+	} `json:"system"`
+}
+
+// Get System Info
+func (p *Bulb) Info() (*Info, error) {
+	return p.InfoContext(context.Background())
+}
+
+func (p *Bulb) InfoContext(ctx context.Context) (*Info, error) {
+	info, err := p.BulbInfoContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &info.Info, nil
+}
+
+// Get System Info, including the bulb light state and presets
+func (p *Bulb) BulbInfo() (*BulbInfo, error) {
+	return p.BulbInfoContext(context.Background())
+}
+
+func (p *Bulb) BulbInfoContext(ctx context.Context) (*BulbInfo, error) {
+	r := bulbInfoResponse{}
+	if err := p.call(ctx, getInfoRequest(), &r); err != nil {
+		return nil, err
+	}
+
+	if r.System.Info == nil {
+		return nil, ErrUnsupported
+	}
+	return r.System.Info, nil
+}
+
+// Reboot
+func (p *Bulb) Reboot() (string, error) {
+	return p.RebootContext(context.Background())
+}
+
+func (p *Bulb) RebootContext(ctx context.Context) (string, error) {
+	return p.send(ctx, bulbRebootRequest())
+}
+
+// Set alias/name
+func (p *Bulb) SetAlias(alias string) error {
+	return p.SetAliasContext(context.Background(), alias)
+}
+
+func (p *Bulb) SetAliasContext(ctx context.Context, alias string) error {
+	_, err := p.do(ctx, bulbSetAliasRequest(alias))
+	return err
+}
+
+// Gets the light state
+func (p *Bulb) LightState() (*LightState, error) {
+	return p.LightStateContext(context.Background())
+}
+
+func (p *Bulb) LightStateContext(ctx context.Context) (*LightState, error) {
+	if p.lightStrip {
+		r, err := p.do(ctx, getStripLightStateRequest())
+		if err != nil {
+			return nil, err
+		}
+
+		if r.LightStrip.GetLightState == nil {
+			return nil, ErrUnsupported
+		}
+		return r.LightStrip.GetLightState, nil
+	}
+
+	r, err := p.do(ctx, getLightStateRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Lighting.GetLightState == nil {
+		return nil, ErrUnsupported
+	}
+	return r.Lighting.GetLightState, nil
+}
+
+func (p *Bulb) transition(ctx context.Context, state *lightStateParams) error {
+	state.TransitionPeriod = int(p.Transition / time.Millisecond)
+	state.IgnoreDefault = 1
+
+	req := transitionLightStateRequest(state)
+	if p.lightStrip {
+		req = setStripLightStateRequest(state)
+	}
+	_, err := p.do(ctx, req)
+	return err
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// Turn On
+func (p *Bulb) TurnOn() error {
+	return p.TurnOnContext(context.Background())
+}
+
+func (p *Bulb) TurnOnContext(ctx context.Context) error {
+	return p.transition(ctx, &lightStateParams{OnOff: intPtr(1)})
+}
+
+// Turn Off
+func (p *Bulb) TurnOff() error {
+	return p.TurnOffContext(context.Background())
+}
+
+func (p *Bulb) TurnOffContext(ctx context.Context) error {
+	return p.transition(ctx, &lightStateParams{OnOff: intPtr(0)})
+}
+
+// Gets the brightness, 0 to 100
+func (p *Bulb) Brightness() (int, error) {
+	return p.BrightnessContext(context.Background())
+}
+
+func (p *Bulb) BrightnessContext(ctx context.Context) (int, error) {
+	state, err := p.LightStateContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if !state.IsOn() && state.DefaultOnState != nil {
+		return state.DefaultOnState.Brightness, nil
+	}
+	return state.Brightness, nil
+}
+
+// Sets the brightness, 1 to 100, and turns the bulb on
+func (p *Bulb) SetBrightness(level int) error {
+	return p.SetBrightnessContext(context.Background(), level)
+}
+
+func (p *Bulb) SetBrightnessContext(ctx context.Context, level int) error {
+	if err := validBrightness(level); err != nil {
+		return err
+	}
+	return p.transition(ctx, &lightStateParams{OnOff: intPtr(1), Brightness: intPtr(level)})
+}
+
+// Color temperature range of the KL and LB series, in Kelvin
+const (
+	MIN_COLOR_TEMP = 2500
+	MAX_COLOR_TEMP = 9000
+)
+
+func validColorTemp(kelvin int) error {
+	if kelvin < MIN_COLOR_TEMP || kelvin > MAX_COLOR_TEMP {
+		return fmt.Errorf("invalid color temperature %d, must be between %d and %d: %w", kelvin, MIN_COLOR_TEMP, MAX_COLOR_TEMP, ErrInvalidArgument)
+	}
+	return nil
+}
+
+// Sets the color temperature in Kelvin, 2500 to 9000, and turns the bulb on
+func (p *Bulb) SetColorTemp(kelvin int) error {
+	return p.SetColorTempContext(context.Background(), kelvin)
+}
+
+func (p *Bulb) SetColorTempContext(ctx context.Context, kelvin int) error {
+	if err := validColorTemp(kelvin); err != nil {
+		return err
+	}
+	return p.transition(ctx, &lightStateParams{OnOff: intPtr(1), ColorTemp: intPtr(kelvin)})
+}
+
+// Sets the color, hue 0 to 360, saturation 0 to 100 and brightness 1 to 100, and turns the bulb on
+func (p *Bulb) SetHSV(hue int, saturation int, brightness int) error {
+	return p.SetHSVContext(context.Background(), hue, saturation, brightness)
+}
+
+func (p *Bulb) SetHSVContext(ctx context.Context, hue int, saturation int, brightness int) error {
+	if err := validHSV(hue, saturation, brightness); err != nil {
+		return err
+	}
+
+	// a color temperature takes precedence over the color
+	return p.transition(ctx, &lightStateParams{
+		OnOff:      intPtr(1),
+		Hue:        intPtr(hue),
+		Saturation: intPtr(saturation),
+		Brightness: intPtr(brightness),
+		ColorTemp:  intPtr(0),
+	})
+}
+
+func validHSV(hue int, saturation int, brightness int) error {
+	if hue < 0 || hue > 360 {
+		return fmt.Errorf("invalid hue %d, must be between 0 and 360", hue)
+	}
+
+	if saturation < 0 || saturation > 100 {
+		return fmt.Errorf("invalid saturation %d, must be between 0 and 100", saturation)
+	}
+	return validBrightness(brightness)
+}
+
+// Gets the presets saved on the bulb
+func (p *Bulb) Presets() ([]PreferredState, error) {
+	return p.PresetsContext(context.Background())
+}
+
+func (p *Bulb) PresetsContext(ctx context.Context) ([]PreferredState, error) {
+	info, err := p.BulbInfoContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return info.PreferredState, nil
+}
+
+// Saves a preset, replacing the one with the same index
+func (p *Bulb) SavePreset(preset PreferredState) error {
+	return p.SavePresetContext(context.Background(), preset)
+}
+
+func (p *Bulb) SavePresetContext(ctx context.Context, preset PreferredState) error {
+	_, err := p.do(ctx, setPreferredStateRequest(preset))
+	return err
+}
+
+// Applies a preset and turns the bulb on
+func (p *Bulb) ApplyPreset(preset PreferredState) error {
+	return p.ApplyPresetContext(context.Background(), preset)
+}
+
+func (p *Bulb) ApplyPresetContext(ctx context.Context, preset PreferredState) error {
+	return p.transition(ctx, &lightStateParams{
+		OnOff:      intPtr(1),
+		Hue:        intPtr(preset.Hue),
+		Saturation: intPtr(preset.Saturation),
+		ColorTemp:  intPtr(preset.ColorTemp),
+		Brightness: intPtr(preset.Brightness),
+	})
+}
+
+func NewBulb(ip string, timeout time.Duration, opts ...Option) *Bulb {
+	return &Bulb{client: newClient(ip, timeout, opts...)}
+}
@@ -0,0 +1,92 @@
+package tplink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+const bulbSysInfo = `{"system":{"get_sysinfo":{
+	"sw_ver":"1.8.6 Build 180809 Rel.091659","hw_ver":"1.0","model":"KL130(US)","description":"Smart Wi-Fi LED Bulb with Color Changing",
+	"alias":"Desk","mic_type":"IOT.SMARTBULB","mic_mac":"50C7BF000000","deviceId":"8012","is_dimmable":1,"is_color":1,"is_variable_color_temp":1,
+	"light_state":{"on_off":0,"dft_on_state":{"mode":"normal","hue":120,"saturation":75,"color_temp":0,"brightness":60}},
+	"preferred_state":[{"index":0,"hue":0,"saturation":0,"color_temp":2700,"brightness":50},{"index":1,"hue":240,"saturation":100,"color_temp":0,"brightness":100}],
+	"err_code":0}}}`
+
+func TestBulb(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		GET_INFO: bulbSysInfo,
+		`{"smartlife.iot.smartbulb.lightingservice":{"transition_light_state":{"on_off":1,"hue":240,"saturation":100,"color_temp":0,"brightness":30,"transition_period":500,"ignore_default":1}}}`: `{"smartlife.iot.smartbulb.lightingservice":{"transition_light_state":{"on_off":1,"hue":240,"saturation":100,"color_temp":0,"brightness":30,"err_code":0}}}`,
+		`{"smartlife.iot.smartbulb.lightingservice":{"transition_light_state":{"on_off":0,"transition_period":500,"ignore_default":1}}}`:                                                           `{"smartlife.iot.smartbulb.lightingservice":{"transition_light_state":{"on_off":0,"err_code":0}}}`,
+		`{"smartlife.iot.common.system":{"set_dev_alias":{"alias":"Lamp"}}}`:                                                                                                                       `{"smartlife.iot.common.system":{"set_dev_alias":{"err_code":0}}}`,
+	}}
+
+	d, err := Connect(context.Background(), "10.0.0.1", WithTransport(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bulb, ok := d.(*Bulb)
+	if !ok {
+		t.Fatalf("expecting *Bulb; got %T", d)
+	}
+	bulb.Transition = 500 * time.Millisecond
+
+	info, err := bulb.BulbInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Model != "KL130(US)" || info.IsColor != 1 || info.LightState.IsOn() || len(info.PreferredState) != 2 || info.PreferredState[1].Hue != 240 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	if err := bulb.SetHSV(240, 100, 30); err != nil {
+		t.Error(err)
+	}
+
+	if err := bulb.TurnOff(); err != nil {
+		t.Error(err)
+	}
+
+	if err := bulb.SetAlias("Lamp"); err != nil {
+		t.Error(err)
+	}
+
+	if err := bulb.SetHSV(400, 100, 30); err == nil {
+		t.Error("expecting an error for hue 400")
+	}
+
+	f.sent = nil
+	for _, kelvin := range []int{0, 2499, 9001, 100000} {
+		if err := bulb.SetColorTemp(kelvin); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%dK: expecting %v; got %v", kelvin, ErrInvalidArgument, err)
+		}
+	}
+	if len(f.sent) != 0 {
+		t.Errorf("expecting no request; got %v", f.sent)
+	}
+}
+
+func TestScanResultDevice(t *testing.T) {
+	tt := []struct {
+		info      Info
+		expecting string
+	}{
+		{Info{Model: "KL130(US)", MicType: "IOT.SMARTBULB"}, "*tplink.Bulb"},
+		{Info{Model: "HS110(US)", Type: "IOT.SMARTPLUGSWITCH", Feature: "TIM:ENE"}, "*tplink.HS110"},
+	}
+
+	for _, v := range tt {
+		d, err := ScanResult{IPAddress: "10.0.0.1", Info: v.info}.Device()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if typ := fmt.Sprintf("%T", d); typ != v.expecting {
+			t.Errorf("expecting %s; got %s", v.expecting, typ)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package tplink
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Devices with a clock, such as HS100 and the types embedding it
+type Clock interface {
+	Addr() string
+	TimeContext(ctx context.Context) (time.Time, error)
+	SetTimeContext(ctx context.Context, t time.Time) error
+}
+
+var _ Clock = (*HS100)(nil)
+
+// Number of devices SyncClocks talks to at once
+const syncConcurrency = 16
+
+// Outcome of a clock check for one device
+type ClockReport struct {
+	Addr       string
+	DeviceTime time.Time     // as read before any resync
+	Offset     time.Duration // device clock minus host clock, positive when the device is ahead
+	RTT        time.Duration
+	Resynced   bool
+	Err        error
+}
+
+func (r ClockReport) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: failed: %s", r.Addr, r.Err)
+	}
+
+	s := fmt.Sprintf("%s: offset %s (rtt %s)", r.Addr, r.Offset, r.RTT)
+	if r.Resynced {
+		s += ", resynced"
+	}
+	return s
+}
+
+// Measures the clock offset of a device, compensating for the round trip.
+// The device reports whole seconds, so the offset is only accurate to half a second.
+func MeasureClock(ctx context.Context, d Clock) ClockReport {
+	r := ClockReport{Addr: d.Addr()}
+
+	start := time.Now()
+	t, err := d.TimeContext(ctx)
+	end := time.Now()
+	if err != nil {
+		r.Err = err
+		return r
+	}
+
+	r.DeviceTime = t
+	r.RTT = end.Sub(start)
+	// the reply was built half way through the round trip, somewhere within the reported second
+	host := start.Add(r.RTT / 2)
+	r.Offset = t.Add(500 * time.Millisecond).Sub(host).Round(time.Millisecond)
+	return r
+}
+
+// Measures the clock of every device and sets the time of the ones drifting by more than threshold,
+// keeping their time zone. A zero threshold only reports. Reports are in the order of devices.
+func SyncClocks(ctx context.Context, devices []Clock, threshold time.Duration) []ClockReport {
+	reports := make([]ClockReport, len(devices))
+	sem := make(chan struct{}, syncConcurrency)
+
+	var wg sync.WaitGroup
+	for i, d := range devices {
+		wg.Add(1)
+		go func(i int, d Clock) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			reports[i] = syncClock(ctx, d, threshold)
+		}(i, d)
+	}
+	wg.Wait()
+	return reports
+}
+
+func syncClock(ctx context.Context, d Clock, threshold time.Duration) ClockReport {
+	r := MeasureClock(ctx, d)
+	if r.Err != nil || threshold <= 0 || (r.Offset <= threshold && r.Offset >= -threshold) {
+		return r
+	}
+
+	// the command reaches the device half a round trip later
+	now := time.Now().Add(r.RTT / 2).In(r.DeviceTime.Location())
+	if err := d.SetTimeContext(ctx, now); err != nil {
+		r.Err = fmt.Errorf("resync failed: %w", err)
+		return r
+	}
+
+	r.Resynced = true
+	return r
+}
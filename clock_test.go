@@ -0,0 +1,82 @@
+package tplink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a device whose clock is offset from the host clock
+type fakeClock struct {
+	mu     sync.Mutex
+	offset time.Duration
+	set    *time.Time
+}
+
+func (c *fakeClock) Exec(ctx context.Context, ip string, cmd string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var req request
+	if err := json.Unmarshal([]byte(cmd), &req); err != nil {
+		return "", err
+	}
+
+	if p := req.Time.SetTimeZone; p != nil {
+		t := time.Date(p.Year, time.Month(p.Month), p.Day, p.Hour, p.Minute, p.Second, 0, time.UTC)
+		c.set = &t
+		c.offset = 0
+		return `{"time":{"set_timezone":{"err_code":0}}}`, nil
+	}
+
+	t := time.Now().Add(c.offset).UTC()
+	return fmt.Sprintf(`{"time":{"get_time":{"year":%d,"month":%d,"mday":%d,"hour":%d,"min":%d,"sec":%d,"err_code":0},"get_timezone":{"index":38,"err_code":0}}}`,
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()), nil
+}
+
+func TestSyncClocks(t *testing.T) {
+	fast := &fakeClock{offset: 3 * time.Minute}
+	slow := &fakeClock{offset: -10 * time.Second}
+	fine := &fakeClock{}
+
+	devices := []Clock{
+		NewHS100("10.0.0.1", time.Second, WithTransport(fast)),
+		NewHS110("10.0.0.2", time.Second, WithTransport(slow)),
+		NewHS100("10.0.0.3", time.Second, WithTransport(fine)),
+		NewHS100("10.0.0.4", time.Second, WithTransport(&fakeTransport{err: context.DeadlineExceeded})),
+	}
+
+	reports := SyncClocks(context.Background(), devices, 30*time.Second)
+	if len(reports) != 4 {
+		t.Fatalf("expecting 4 reports; got %d", len(reports))
+	}
+
+	tt := []struct {
+		offset   time.Duration
+		resynced bool
+	}{
+		{3 * time.Minute, true},
+		{-10 * time.Second, false},
+		{0, false},
+	}
+	for i, v := range tt {
+		r := reports[i]
+		if r.Err != nil || r.Resynced != v.resynced {
+			t.Errorf("%s: unexpected report %s", r.Addr, r)
+		}
+		if d := r.Offset - v.offset; d < -time.Second || d > time.Second {
+			t.Errorf("%s: expecting offset %s; got %s", r.Addr, v.offset, r.Offset)
+		}
+	}
+
+	if fast.set == nil || time.Since(*fast.set) > 2*time.Second || slow.set != nil {
+		t.Errorf("unexpected resync %v %v", fast.set, slow.set)
+	}
+
+	if reports[3].Addr != "10.0.0.4" || reports[3].Err == nil {
+		t.Errorf("expecting an error; got %s", reports[3])
+	}
+}
@@ -0,0 +1,84 @@
+package tplink
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func newCountdownParams(id string, name string, delay time.Duration, action Action, enable int) (*countdownParams, error) {
+	seconds := int(delay / time.Second)
+	if seconds < 1 {
+		return nil, fmt.Errorf("invalid countdown delay %s, must be at least one second", delay)
+	}
+	return &countdownParams{ID: id, Enable: enable, Delay: seconds, Action: action, Name: name}, nil
+}
+
+// Runs action once delay has elapsed, e.g. AddCountdown(45*time.Minute, OFF).
+// Most devices accept a single countdown at a time. Returns the ID of the countdown rule.
+func (p *HS100) AddCountdown(delay time.Duration, action Action) (string, error) {
+	return p.AddCountdownContext(context.Background(), delay, action)
+}
+
+func (p *HS100) AddCountdownContext(ctx context.Context, delay time.Duration, action Action) (string, error) {
+	rule, err := newCountdownParams("", "countdown", delay, action, ENABLED)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := p.do(ctx, addCountdownRuleRequest(rule))
+	if err != nil {
+		return "", err
+	}
+
+	return r.Countdown.AddRule.ID, nil
+}
+
+// Gets Countdown Rules, with their remaining time
+func (p *HS100) Countdowns() ([]CountdownRule, error) {
+	return p.CountdownsContext(context.Background())
+}
+
+func (p *HS100) CountdownsContext(ctx context.Context) ([]CountdownRule, error) {
+	r, err := p.do(ctx, getCountdownRulesRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Countdown.Rule.List, nil
+}
+
+// Edit Countdown Rule with given ID, restarting it
+func (p *HS100) EditCountdown(rule CountdownRule) error {
+	return p.EditCountdownContext(context.Background(), rule)
+}
+
+func (p *HS100) EditCountdownContext(ctx context.Context, rule CountdownRule) error {
+	params, err := newCountdownParams(rule.ID, rule.Name, time.Duration(rule.Delay)*time.Second, rule.Action, rule.Enable)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.do(ctx, editCountdownRuleRequest(params))
+	return err
+}
+
+// Cancel Countdown Rule with given ID
+func (p *HS100) CancelCountdown(id string) error {
+	return p.CancelCountdownContext(context.Background(), id)
+}
+
+func (p *HS100) CancelCountdownContext(ctx context.Context, id string) error {
+	_, err := p.do(ctx, deleteCountdownRuleRequest(id))
+	return err
+}
+
+// Cancel All Countdown Rules
+func (p *HS100) CancelAllCountdowns() error {
+	return p.CancelAllCountdownsContext(context.Background())
+}
+
+func (p *HS100) CancelAllCountdownsContext(ctx context.Context) error {
+	_, err := p.do(ctx, deleteAllCountdownRulesRequest())
+	return err
+}
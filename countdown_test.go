@@ -0,0 +1,43 @@
+package tplink
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCountdown(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		`{"count_down":{"add_rule":{"enable":1,"delay":2700,"act":0,"name":"countdown"}}}`: `{"count_down":{"add_rule":{"id":"7C90311A1CD3227F25C6001D88F7FC13","err_code":0}}}`,
+		`{"count_down":{"get_rules":null}}`:                                                `{"count_down":{"get_rules":{"rule_list":[{"id":"7C90311A1CD3227F25C6001D88F7FC13","name":"countdown","enable":1,"delay":2700,"act":0,"remain":2695}],"err_code":0}}}`,
+		`{"count_down":{"delete_rule":{"id":"7C90311A1CD3227F25C6001D88F7FC13"}}}`:         `{"count_down":{"delete_rule":{"err_code":0}}}`,
+	}}
+	p := NewHS100("10.0.0.1", time.Second, WithTransport(f))
+
+	id, err := p.AddCountdown(45*time.Minute, OFF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "7C90311A1CD3227F25C6001D88F7FC13" {
+		t.Errorf("unexpected id %q", id)
+	}
+
+	rules, err := p.Countdowns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || !rules[0].IsEnabled() || rules[0].Action != OFF || rules[0].RemainingTime() != 2695*time.Second {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+
+	if err := p.CancelCountdown(id); err != nil {
+		t.Error(err)
+	}
+
+	f.sent = nil
+	if _, err := p.AddCountdown(500*time.Millisecond, ON); err == nil {
+		t.Error("expecting an error for a delay under one second")
+	}
+	if len(f.sent) != 0 {
+		t.Errorf("expecting no request; got %v", f.sent)
+	}
+}
@@ -0,0 +1,110 @@
+package tplink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Device is implemented by every supported device
+type Device interface {
+	Info() (*Info, error)
+	InfoContext(ctx context.Context) (*Info, error)
+	TurnOn() error
+	TurnOnContext(ctx context.Context) error
+	TurnOff() error
+	TurnOffContext(ctx context.Context) error
+	SetAlias(alias string) error
+	SetAliasContext(ctx context.Context, alias string) error
+	Reboot() (string, error)
+	RebootContext(ctx context.Context) (string, error)
+	Features() (Features, error)
+	FeaturesContext(ctx context.Context) (Features, error)
+}
+
+// EnergyMeter is implemented by devices measuring their power consumption, e.g. HS110
+type EnergyMeter interface {
+	Meter() (*Meter, error)
+	MeterContext(ctx context.Context) (*Meter, error)
+	DailyStats(month int, year int) ([]*DailyUsage, error)
+	DailyStatsContext(ctx context.Context, month int, year int) ([]*DailyUsage, error)
+	MonthlyStats(year int) ([]*MonthlyUsage, error)
+	MonthlyStatsContext(ctx context.Context, year int) ([]*MonthlyUsage, error)
+}
+
+// Dimmer is implemented by devices with an adjustable brightness, e.g. HS220
+type Dimmer interface {
+	Brightness() (int, error)
+	BrightnessContext(ctx context.Context) (int, error)
+	SetBrightness(level int) error
+	SetBrightnessContext(ctx context.Context, level int) error
+}
+
+// MultiOutlet is implemented by power strips whose outlets are controlled individually, e.g. HS300
+type MultiOutlet interface {
+	Outlets() ([]Device, error)
+	OutletsContext(ctx context.Context) ([]Device, error)
+}
+
+var (
+	_ Device      = (*HS100)(nil)
+	_ Device      = (*HS105)(nil)
+	_ Device      = (*HS110)(nil)
+	_ EnergyMeter = (*HS110)(nil)
+	_ Device      = (*HS300)(nil)
+	_ MultiOutlet = (*HS300)(nil)
+	_ Device      = (*HS300Outlet)(nil)
+	_ EnergyMeter = (*HS300Outlet)(nil)
+	_ Device      = (*HS220)(nil)
+	_ Dimmer      = (*HS220)(nil)
+	_ Device      = (*Bulb)(nil)
+	_ Dimmer      = (*Bulb)(nil)
+	_ Device      = (*LightStrip)(nil)
+)
+
+// Timeout used by Connect unless WithTimeout is given
+const DefaultTimeout = 2 * time.Second
+
+// Connect queries the device at ip and returns the type matching its model and features.
+// Capabilities can then be checked with a type assertion:
+//
+//	d, err := tplink.Connect(ctx, ip)
+//	if m, ok := d.(tplink.EnergyMeter); ok {
+//		meter, err := m.Meter()
+//	}
+func Connect(ctx context.Context, ip string, opts ...Option) (Device, error) {
+	p := NewHS100(ip, DefaultTimeout, opts...)
+	info, err := p.InfoContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newDevice(p.client, info)
+}
+
+// newDevice returns the type matching the model and features of a device
+func newDevice(c client, info *Info) (Device, error) {
+	c.features = info.Features()
+	model := info.Model
+	if i := strings.Index(model, "("); i >= 0 {
+		model = model[:i]
+	}
+
+	switch {
+	case info.IsBulb() && strings.HasPrefix(model, "KL4"):
+		return &LightStrip{Bulb{client: c, lightStrip: true}}, nil
+	case info.IsBulb():
+		return &Bulb{client: c}, nil
+	case len(info.Children) > 0:
+		return &HS300{HS100: HS100{c}, deviceID: info.DeviceID}, nil
+	case model == "HS220" || model == "ES20M":
+		return &HS220{HS100{c}}, nil
+	case model == "HS105":
+		return &HS105{HS100{c}}, nil
+	case c.features.HasEnergyMeter():
+		return &HS110{HS100{c}}, nil
+	case info.Type == "IOT.SMARTPLUGSWITCH":
+		return &HS100{c}, nil
+	}
+	return nil, fmt.Errorf("unsupported device: model=%s, type=%s", info.Model, info.Type)
+}
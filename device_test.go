@@ -0,0 +1,46 @@
+package tplink
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestConnect(t *testing.T) {
+	tt := []struct {
+		sysinfo   string
+		expecting string
+		meter     bool
+	}{
+		{`{"model":"HS100(US)","type":"IOT.SMARTPLUGSWITCH","feature":"TIM"}`, "*tplink.HS100", false},
+		{`{"model":"HS105(US)","type":"IOT.SMARTPLUGSWITCH","feature":"TIM"}`, "*tplink.HS105", false},
+		{`{"model":"HS110(EU)","type":"IOT.SMARTPLUGSWITCH","feature":"TIM:ENE"}`, "*tplink.HS110", true},
+		{`{"model":"KP115(US)","type":"IOT.SMARTPLUGSWITCH","feature":"TIM:ENE"}`, "*tplink.HS110", true},
+		{`{"model":"XX999(US)","type":"IOT.UNKNOWN","feature":""}`, "", false},
+	}
+
+	for _, v := range tt {
+		f := &fakeTransport{replies: map[string]string{
+			GET_INFO: `{"system":{"get_sysinfo":` + v.sysinfo + `}}`,
+		}}
+
+		d, err := Connect(context.Background(), "10.0.0.1", WithTransport(f))
+		if v.expecting == "" {
+			if err == nil {
+				t.Errorf("%s: expecting an error", v.sysinfo)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if typ := fmt.Sprintf("%T", d); typ != v.expecting {
+			t.Errorf("expecting %s; got %s", v.expecting, typ)
+		}
+
+		if _, ok := d.(EnergyMeter); ok != v.meter {
+			t.Errorf("%s: expecting EnergyMeter=%t", v.sysinfo, v.meter)
+		}
+	}
+}
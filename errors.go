@@ -0,0 +1,113 @@
+package tplink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// DeviceError is returned when the device answers a command with a non zero err_code
+type DeviceError struct {
+	Module  string // e.g. "emeter"
+	Method  string // e.g. "get_realtime", empty when the whole module was rejected
+	Code    int
+	Message string
+}
+
+func (e *DeviceError) Error() string {
+	name := e.Module
+	if e.Method != "" {
+		name += "." + e.Method
+	}
+	return fmt.Sprintf("%s failed. Error code=%d, msg: %s", name, e.Code, e.Message)
+}
+
+// Is matches the sentinel errors below by code, so that
+// errors.Is(err, ErrModuleNotSupported) works whatever the module is.
+// Module and method not supported errors also match ErrUnsupported.
+func (e *DeviceError) Is(target error) bool {
+	if target == ErrUnsupported {
+		return e.Code == ErrModuleNotSupported.Code || e.Code == ErrMethodNotSupported.Code
+	}
+
+	t, ok := target.(*DeviceError)
+	return ok && t.Module == "" && t.Method == "" && t.Code == e.Code
+}
+
+// ErrUnsupported is returned when calling a method the device lacks
+var ErrUnsupported = errors.New("not supported by the device")
+
+// Common firmware error codes
+var (
+	ErrModuleNotSupported = &DeviceError{Code: -1, Message: "module not support"}
+	ErrMethodNotSupported = &DeviceError{Code: -2, Message: "method not support"}
+	ErrInvalidArgument    = &DeviceError{Code: -3, Message: "invalid argument"}
+)
+
+// NetworkError is returned when the device could not be reached or did not answer
+type NetworkError struct {
+	Addr string
+	Err  error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("failed to reach %s: %s", e.Addr, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+type errorStatus struct {
+	ErrorCode    *int   `json:"err_code"`
+	ErrorMessage string `json:"err_msg"`
+}
+
+// deviceErrors returns the errors reported in a reply, sorted by module and method.
+// Errors are reported either per method, {"module":{"method":{"err_code":-2}}},
+// or for the whole module, {"module":{"err_code":-1}}.
+func deviceErrors(data string) ([]*DeviceError, error) {
+	modules := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(data), &modules); err != nil {
+		return nil, err
+	}
+
+	errs := []*DeviceError{}
+	for module, raw := range modules {
+		methods := map[string]json.RawMessage{}
+		if err := json.Unmarshal(raw, &methods); err != nil {
+			// not an object, nothing to check
+			continue
+		}
+
+		if _, ok := methods["err_code"]; ok {
+			if e := statusError(module, "", raw); e != nil {
+				errs = append(errs, e)
+			}
+			continue
+		}
+
+		for method, raw := range methods {
+			if e := statusError(module, method, raw); e != nil {
+				errs = append(errs, e)
+			}
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].Module != errs[j].Module {
+			return errs[i].Module < errs[j].Module
+		}
+		return errs[i].Method < errs[j].Method
+	})
+	return errs, nil
+}
+
+func statusError(module string, method string, raw json.RawMessage) *DeviceError {
+	s := errorStatus{}
+	if err := json.Unmarshal(raw, &s); err != nil || s.ErrorCode == nil || *s.ErrorCode == 0 {
+		return nil
+	}
+	return &DeviceError{Module: module, Method: method, Code: *s.ErrorCode, Message: s.ErrorMessage}
+}
@@ -0,0 +1,73 @@
+package tplink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeviceErrors(t *testing.T) {
+	tt := []struct {
+		data      string
+		expecting []DeviceError
+	}{
+		{`{"system":{"set_relay_state":{"err_code":0}}}`, nil},
+		{`{"system":{"get_sysinfo":{"alias":"Plug1","err_code":0}}}`, nil},
+		{`{"emeter":{"err_code":-1,"err_msg":"module not support"}}`, []DeviceError{{"emeter", "", -1, "module not support"}}},
+		{
+			`{"system":{"get_sysinfo":{"err_code":0}},"schedule":{"get_rules":{"err_code":-2,"err_msg":"method not support"},"add_rule":{"err_code":-3,"err_msg":"invalid argument"}}}`,
+			[]DeviceError{{"schedule", "add_rule", -3, "invalid argument"}, {"schedule", "get_rules", -2, "method not support"}},
+		},
+	}
+
+	for _, v := range tt {
+		errs, err := deviceErrors(v.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(errs) != len(v.expecting) {
+			t.Errorf("%s: expecting %d errors; got %d", v.data, len(v.expecting), len(errs))
+			continue
+		}
+
+		for i, e := range errs {
+			if *e != v.expecting[i] {
+				t.Errorf("expecting %+v; got %+v", v.expecting[i], *e)
+			}
+		}
+	}
+}
+
+func TestErrorClasses(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		`{"emeter":{"get_realtime":{}}}`: `{"emeter":{"err_code":-1,"err_msg":"module not support"}}`,
+		GET_TIMEZONE:                     `{"time":{"get_timezone":{"err_code":-2,"err_msg":"method not support"}}}`,
+	}}
+	plug := NewHS110("10.0.0.1", time.Second, WithTransport(f))
+
+	_, err := plug.Meter()
+	var devErr *DeviceError
+	if !errors.As(err, &devErr) || devErr.Module != "emeter" {
+		t.Errorf("expecting an emeter DeviceError; got %v", err)
+	}
+	if !errors.Is(err, ErrModuleNotSupported) || errors.Is(err, ErrMethodNotSupported) {
+		t.Errorf("expecting %v; got %v", ErrModuleNotSupported, err)
+	}
+
+	_, err = plug.TimeZone()
+	if !errors.Is(err, ErrMethodNotSupported) {
+		t.Errorf("expecting %v; got %v", ErrMethodNotSupported, err)
+	}
+
+	f.err = context.DeadlineExceeded
+	_, err = plug.Meter()
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expecting a NetworkError; got %v", err)
+	}
+	if errors.As(err, &devErr) {
+		t.Errorf("expecting no DeviceError; got %v", err)
+	}
+}
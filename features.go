@@ -0,0 +1,75 @@
+package tplink
+
+import (
+	"context"
+	"strings"
+)
+
+// An optional feature advertised by a device in Info.Feature
+type Feature string
+
+const (
+	TIMER        Feature = "TIM"
+	ENERGY_METER Feature = "ENE"
+)
+
+// Features is the set of optional features of a device
+type Features []Feature
+
+// Parse a feature string such as "TIM:ENE"
+func ParseFeatures(s string) Features {
+	features := Features{}
+	for _, f := range strings.Split(s, ":") {
+		if f = strings.TrimSpace(f); f != "" {
+			features = append(features, Feature(f))
+		}
+	}
+	return features
+}
+
+func (f Features) Has(feature Feature) bool {
+	for _, v := range f {
+		if v == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Features) HasTimer() bool {
+	return f.Has(TIMER)
+}
+
+func (f Features) HasEnergyMeter() bool {
+	return f.Has(ENERGY_METER)
+}
+
+// Features of the device. When the device was created with Connect they are known
+// already, otherwise they are read from the device.
+func (c *client) Features() (Features, error) {
+	return c.FeaturesContext(context.Background())
+}
+
+func (c *client) FeaturesContext(ctx context.Context) (Features, error) {
+	if c.features != nil {
+		return c.features, nil
+	}
+
+	r, err := c.do(ctx, getInfoRequest())
+	if err != nil {
+		return nil, err
+	}
+	if r.System.Info == nil {
+		return nil, ErrUnsupported
+	}
+	return r.System.Info.Features(), nil
+}
+
+// require fails with ErrUnsupported, without talking to the device, when the
+// features of the device are known and feature is not one of them
+func (c *client) require(feature Feature) error {
+	if c.features != nil && !c.features.Has(feature) {
+		return ErrUnsupported
+	}
+	return nil
+}
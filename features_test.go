@@ -0,0 +1,57 @@
+package tplink
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFeatures(t *testing.T) {
+	tt := []struct {
+		s         string
+		expecting Features
+	}{
+		{"", Features{}},
+		{"TIM", Features{TIMER}},
+		{"TIM:ENE", Features{TIMER, ENERGY_METER}},
+	}
+
+	for _, v := range tt {
+		f := ParseFeatures(v.s)
+		if !reflect.DeepEqual(f, v.expecting) {
+			t.Errorf("expecting %v; got %v", v.expecting, f)
+		}
+
+		if f.HasEnergyMeter() != (v.s == "TIM:ENE") || f.HasTimer() != (v.s != "") {
+			t.Errorf("%s: unexpected HasEnergyMeter=%t, HasTimer=%t", v.s, f.HasEnergyMeter(), f.HasTimer())
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		// no emeter module in the reply
+		`{"emeter":{"get_monthstat":{"year":2018}}}`:         `{}`,
+		`{"emeter":{"get_daystat":{"month":3,"year":2018}}}`: `{"emeter":{"err_code":-1,"err_msg":"module not support"}}`,
+	}}
+	plug := NewHS110("10.0.0.1", time.Second, WithTransport(f))
+
+	if _, err := plug.MonthlyStats(2018); err != ErrUnsupported {
+		t.Errorf("expecting %v; got %v", ErrUnsupported, err)
+	}
+
+	if _, err := plug.DailyStats(3, 2018); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expecting %v; got %v", ErrUnsupported, err)
+	}
+
+	// known features, fails without a round trip
+	f.sent = nil
+	plug.features = Features{TIMER}
+	if _, err := plug.Meter(); err != ErrUnsupported {
+		t.Errorf("expecting %v; got %v", ErrUnsupported, err)
+	}
+	if len(f.sent) != 0 {
+		t.Errorf("expecting no request; got %v", f.sent)
+	}
+}
@@ -0,0 +1,160 @@
+package tplink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	// how long to wait for the device to reboot after flashing, when it does not report a reboot time
+	defaultRebootTimeout = 2 * time.Minute
+	// added to the flash and reboot times reported by the device
+	rebootMargin = 30 * time.Second
+)
+
+// Set the interval between polls of the download state and of the device while it reboots, one second by default
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *client) {
+		c.poll = interval
+	}
+}
+
+// Ask the device to download the firmware image at url
+func (p *HS100) DownloadFirmware(url string) error {
+	return p.DownloadFirmwareContext(context.Background(), url)
+}
+
+func (p *HS100) DownloadFirmwareContext(ctx context.Context, url string) error {
+	_, err := p.do(ctx, downloadFirmwareRequest(url))
+	return err
+}
+
+// Gets the progress of the firmware download
+func (p *HS100) DownloadState() (*DownloadState, error) {
+	return p.DownloadStateContext(context.Background())
+}
+
+func (p *HS100) DownloadStateContext(ctx context.Context) (*DownloadState, error) {
+	r, err := p.do(ctx, getDownloadStateRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	state := r.System.DownloadState.DownloadState
+	return &state, nil
+}
+
+// Flash the downloaded firmware, the device reboots afterwards
+func (p *HS100) FlashFirmware() error {
+	return p.FlashFirmwareContext(context.Background())
+}
+
+func (p *HS100) FlashFirmwareContext(ctx context.Context) error {
+	_, err := p.do(ctx, flashFirmwareRequest())
+	return err
+}
+
+// Download and flash the firmware at url, then wait for the device to come back with a new software version.
+// progressFn, if not nil, is called with the download percentage. ctx bounds the whole upgrade, the reboot
+// itself is bounded by the flash and reboot times reported by the device or two minutes.
+// Returns the new software version, or an error if the device comes back with the previous one.
+func (p *HS100) UpgradeFirmware(ctx context.Context, url string, progressFn func(percent int)) (string, error) {
+	info, err := p.InfoContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	previous := info.SoftwareVersion
+
+	if err := p.DownloadFirmwareContext(ctx, url); err != nil {
+		return "", err
+	}
+
+	state, err := p.waitDownload(ctx, progressFn)
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.FlashFirmwareContext(ctx); err != nil {
+		return "", err
+	}
+
+	timeout := defaultRebootTimeout
+	if state.RebootTime > 0 {
+		timeout = time.Duration(state.FlashTime+state.RebootTime)*time.Second + rebootMargin
+	}
+	return p.waitReboot(ctx, previous, timeout)
+}
+
+func (p *HS100) waitDownload(ctx context.Context, progressFn func(percent int)) (*DownloadState, error) {
+	last := -1
+	for {
+		state, err := p.DownloadStateContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if state.Status < 0 {
+			return nil, fmt.Errorf("firmware download failed with status %d at %d%%", state.Status, state.Ratio)
+		}
+
+		if progressFn != nil && state.Ratio != last {
+			progressFn(state.Ratio)
+		}
+		last = state.Ratio
+
+		if state.Ratio >= 100 {
+			return state, nil
+		}
+
+		if err := sleep(ctx, p.poll); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitReboot polls the device until it answers with a software version other than previous,
+// failing once timeout has elapsed or when the device is back after a reboot with previous
+func (p *HS100) waitReboot(ctx context.Context, previous string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	rebooted := false
+	for {
+		if err := sleep(ctx, p.poll); err != nil {
+			return "", fmt.Errorf("device did not come back with a new firmware version, still %q: %w", previous, err)
+		}
+
+		info, err := p.InfoContext(ctx)
+		if err != nil {
+			var netErr *NetworkError
+			if errors.As(err, &netErr) {
+				rebooted = true // still rebooting
+				continue
+			}
+			return "", err
+		}
+
+		if info.SoftwareVersion != previous {
+			return info.SoftwareVersion, nil
+		}
+
+		if rebooted {
+			return "", fmt.Errorf("firmware was not flashed, device rebooted with version %q", previous)
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
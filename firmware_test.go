@@ -0,0 +1,134 @@
+package tplink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeFirmwareDevice downloads the image served at the requested url and
+// boots into it, the image content being the new software version.
+type fakeFirmwareDevice struct {
+	version string
+	image   string
+	status  int
+	ratio   int
+	down    int  // polls left before the device answers again
+	reject  bool // reboot on the current version when flashing
+}
+
+func (d *fakeFirmwareDevice) Exec(ctx context.Context, ip string, cmd string) (string, error) {
+	var req struct {
+		System map[string]json.RawMessage `json:"system"`
+	}
+	if err := json.Unmarshal([]byte(cmd), &req); err != nil {
+		return "", err
+	}
+
+	if d.down > 0 {
+		d.down--
+		return "", errors.New("connection refused")
+	}
+
+	switch {
+	case req.System["get_sysinfo"] != nil:
+		return fmt.Sprintf(`{"system":{"get_sysinfo":{"sw_ver":%q,"err_code":0}}}`, d.version), nil
+	case req.System["download_firmware"] != nil:
+		var params urlParams
+		json.Unmarshal(req.System["download_firmware"], &params)
+		resp, err := http.Get(params.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			d.status = -1
+		} else {
+			d.status = 1
+			d.image = string(body)
+		}
+		return `{"system":{"download_firmware":{"err_code":0}}}`, nil
+	case req.System["get_download_state"] != nil:
+		if d.status > 0 {
+			d.ratio = min(d.ratio+50, 100)
+		}
+		return fmt.Sprintf(`{"system":{"get_download_state":{"status":%d,"ratio":%d,"reboot_time":5,"flash_time":0,"err_code":0}}}`, d.status, d.ratio), nil
+	case req.System["flash_firmware"] != nil:
+		if !d.reject {
+			d.version = d.image
+		}
+		d.down = 2
+		return `{"system":{"flash_firmware":{"err_code":0}}}`, nil
+	}
+	return "", fmt.Errorf("unexpected command %s", cmd)
+}
+
+func TestUpgradeFirmware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/hs100.bin" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "1.5.6 Build 191125 Rel.083657")
+	}))
+	defer srv.Close()
+
+	d := &fakeFirmwareDevice{version: "1.5.1 Build 171109 Rel.165709"}
+	p := NewHS100("10.0.0.1", time.Second, WithTransport(d), WithPollInterval(time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var progress []int
+	version, err := p.UpgradeFirmware(ctx, srv.URL+"/hs100.bin", func(percent int) {
+		progress = append(progress, percent)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.5.6 Build 191125 Rel.083657" {
+		t.Errorf("unexpected version %q", version)
+	}
+	if !reflect.DeepEqual(progress, []int{50, 100}) {
+		t.Errorf("unexpected progress %v", progress)
+	}
+
+	_, err = p.UpgradeFirmware(ctx, srv.URL+"/missing.bin", nil)
+	if err == nil || !strings.Contains(err.Error(), "download failed") {
+		t.Errorf("expecting a download failure; got %v", err)
+	}
+}
+
+func TestUpgradeFirmwareRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "1.5.6 Build 191125 Rel.083657")
+	}))
+	defer srv.Close()
+
+	d := &fakeFirmwareDevice{version: "1.5.1 Build 171109 Rel.165709", reject: true}
+	p := NewHS100("10.0.0.1", time.Second, WithTransport(d), WithPollInterval(time.Millisecond))
+
+	done := make(chan error)
+	go func() {
+		_, err := p.UpgradeFirmware(context.Background(), srv.URL+"/hs100.bin", nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "1.5.1 Build 171109 Rel.165709") {
+			t.Errorf("expecting an error reporting the previous version; got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("UpgradeFirmware did not return")
+	}
+}
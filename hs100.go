@@ -1,25 +1,25 @@
 package tplink
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"time"
 )
 
+// TP-Link HS100 smart plug.
+// Every method has a XXXContext variant taking a context.Context for cancellation.
 type HS100 struct {
-	ip      string
-	timeout time.Duration
+	client
 }
 
 // Get System Info (Software & Hardware Versions, MAC, deviceID, hwID etc.)
 func (p *HS100) Info() (*Info, error) {
-	data, err := exec(p.ip, GET_INFO, p.timeout)
-	if err != nil {
-		return nil, err
-	}
+	return p.InfoContext(context.Background())
+}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+func (p *HS100) InfoContext(ctx context.Context) (*Info, error) {
+	r, err := p.do(ctx, getInfoRequest())
+	if err != nil {
 		return nil, err
 	}
 
@@ -28,232 +28,178 @@ func (p *HS100) Info() (*Info, error) {
 
 // Reboot
 func (p *HS100) Reboot() (string, error) {
-	return exec(p.ip, REBOOT, p.timeout)
+	return p.RebootContext(context.Background())
+}
+
+func (p *HS100) RebootContext(ctx context.Context) (string, error) {
+	return p.send(ctx, rebootRequest())
 }
 
 // Reset
 func (p *HS100) Reset() (string, error) {
-	return exec(p.ip, RESET, p.timeout)
+	return p.ResetContext(context.Background())
+}
+
+func (p *HS100) ResetContext(ctx context.Context) (string, error) {
+	return p.send(ctx, resetRequest())
 }
 
 // Set alias/name
 func (p *HS100) SetAlias(alias string) error {
-	data, err := exec(p.ip, fmt.Sprintf(SET_ALIAS, alias), p.timeout)
-	if err != nil {
-		return err
-	}
+	return p.SetAliasContext(context.Background(), alias)
+}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
+func (p *HS100) SetAliasContext(ctx context.Context, alias string) error {
+	_, err := p.do(ctx, setAliasRequest(alias))
+	return err
+}
 
-	if r.System.SetAlias.ErrorCode != 0 {
-		return fmt.Errorf("failed to set alias. Error code=%d", r.System.SetAlias.ErrorCode)
+// Set the device location, used by sunrise and sunset rules
+func (p *HS100) SetLocation(latitude float64, longitude float64) error {
+	return p.SetLocationContext(context.Background(), latitude, longitude)
+}
+
+func (p *HS100) SetLocationContext(ctx context.Context, latitude float64, longitude float64) error {
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return fmt.Errorf("invalid location %f,%f", latitude, longitude)
 	}
-	return nil
+
+	_, err := p.do(ctx, setDevLocationRequest(latitude, longitude))
+	return err
 }
 
 // Turn On
 func (p *HS100) TurnOn() error {
-	data, err := exec(p.ip, TURN_ON, p.timeout)
-	if err != nil {
-		return err
-	}
-
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
+	return p.TurnOnContext(context.Background())
+}
 
-	if r.System.SetState.ErrorCode != 0 {
-		return fmt.Errorf("failed to turn the device off. Error code=%d", r.System.SetState.ErrorCode)
-	}
-	return nil
+func (p *HS100) TurnOnContext(ctx context.Context) error {
+	_, err := p.do(ctx, setRelayStateRequest(ON))
+	return err
 }
 
 // Turn Off
 func (p *HS100) TurnOff() error {
-	data, err := exec(p.ip, TURN_OFF, p.timeout)
-	if err != nil {
-		return err
-	}
-
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
+	return p.TurnOffContext(context.Background())
+}
 
-	if r.System.SetState.ErrorCode != 0 {
-		return fmt.Errorf("failed to turn the device off. Error code=%d", r.System.SetState.ErrorCode)
-	}
-	return nil
+func (p *HS100) TurnOffContext(ctx context.Context) error {
+	_, err := p.do(ctx, setRelayStateRequest(OFF))
+	return err
 }
 
 // Turn Led Light On
 func (p *HS100) TurnLedOn() error {
-	data, err := exec(p.ip, TURN_LED_ON, p.timeout)
-	if err != nil {
-		return err
-	}
-
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
+	return p.TurnLedOnContext(context.Background())
+}
 
-	if r.System.SetState.ErrorCode != 0 {
-		return fmt.Errorf("failed to turn the device off. Error code=%d", r.System.SetState.ErrorCode)
-	}
-	return nil
+func (p *HS100) TurnLedOnContext(ctx context.Context) error {
+	_, err := p.do(ctx, setLedRequest(ON))
+	return err
 }
 
 // Turn Led Light Off
 func (p *HS100) TurnLedOff() error {
-	data, err := exec(p.ip, TURN_LED_OFF, p.timeout)
-	if err != nil {
-		return err
-	}
+	return p.TurnLedOffContext(context.Background())
+}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
+func (p *HS100) TurnLedOffContext(ctx context.Context) error {
+	_, err := p.do(ctx, setLedRequest(OFF))
+	return err
+}
 
-	if r.System.SetState.ErrorCode != 0 {
-		return fmt.Errorf("failed to turn the device off. Error code=%d", r.System.SetState.ErrorCode)
-	}
-	return nil
+// Gets the device time zone
+func (p *HS100) TimeZone() (*time.Location, error) {
+	return p.TimeZoneContext(context.Background())
 }
 
-// TODO: return a timezone instead of index
-func (p *HS100) TimeZone() (int, error) {
-	data, err := exec(p.ip, GET_TIMEZONE, p.timeout)
+func (p *HS100) TimeZoneContext(ctx context.Context) (*time.Location, error) {
+	r, err := p.do(ctx, getTimeZoneRequest())
 	if err != nil {
-		return 0, err
-	}
-
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return 0, err
-	}
-
-	tz := r.Time.GetTimeZone
-
-	if tz.ErrorCode != 0 {
-		return 0, fmt.Errorf("failed to get time zone. Error code=%d", r.System.SetState.ErrorCode)
+		return nil, err
 	}
 
-	return tz.Index, nil
+	return TimeZoneLocation(r.Time.GetTimeZone.Index)
 }
 
+// Gets the device time, in the device time zone
 func (p *HS100) Time() (time.Time, error) {
-	data, err := exec(p.ip, GET_TIME, p.timeout)
+	return p.TimeContext(context.Background())
+}
+
+func (p *HS100) TimeContext(ctx context.Context) (time.Time, error) {
+	r, err := p.do(ctx, getTimeAndZoneRequest())
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return time.Time{}, err
+	loc, err := TimeZoneLocation(r.Time.GetTimeZone.Index)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get device timezone: %w", err)
 	}
 
 	t := r.Time.GetTime
+	return time.Date(t.Year, time.Month(t.Month), t.Day, t.Hour, t.Minutes, t.Seconds, 0, loc), nil
+}
 
-	if t.ErrorCode != 0 {
-		return time.Time{}, fmt.Errorf("failed to get time. Error code=%d", r.System.SetState.ErrorCode)
-	}
-
-	// TODO: get timezone
-	//timezone, err := p.TimeZone()
-	// if err != nill {
-	//	return "", fmt.Errorf("failed to get device timezone: %s", err)
-	//}
-
-	loc, err := time.LoadLocation("EST")
-	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to get device timezone: %s", err)
-	}
-	d := time.Date(t.Year, time.Month(t.Month), t.Day, t.Hour, t.Minutes, t.Seconds, 0, loc)
-
-	return d, nil
+// Sets the device time and time zone, the time zone index is picked from t.Location()
+func (p *HS100) SetTime(t time.Time) error {
+	return p.SetTimeContext(context.Background(), t)
 }
 
-func (p *HS100) SetTimeZone(t time.Time) error {
-	// TODO: timezone
-	cmd := fmt.Sprintf(SET_TIMEZONE, t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 18)
-	data, err := exec(p.ip, cmd, p.timeout)
+func (p *HS100) SetTimeContext(ctx context.Context, t time.Time) error {
+	index, err := TimeZoneIndex(t.Location())
 	if err != nil {
 		return err
 	}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
+	_, err = p.do(ctx, setTimeZoneRequest(t, index))
+	return err
+}
 
-	if r.Time.SetTimeZone.ErrorCode != 0 {
-		return fmt.Errorf("failed to set timezone. Error code=%d, msg: %s", r.Time.SetTimeZone.ErrorCode, r.Time.SetTimeZone.ErrorMessage)
-	}
+// Same as SetTime
+func (p *HS100) SetTimeZone(t time.Time) error {
+	return p.SetTimeContext(context.Background(), t)
+}
 
-	return nil
+func (p *HS100) SetTimeZoneContext(ctx context.Context, t time.Time) error {
+	return p.SetTimeContext(ctx, t)
 }
 
 func (p *HS100) ScanWifi() ([]AP, error) {
-	data, err := exec(p.ip, SCAN_WIFI, p.timeout)
-	if err != nil {
-		return nil, err
-	}
+	return p.ScanWifiContext(context.Background())
+}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+func (p *HS100) ScanWifiContext(ctx context.Context) ([]AP, error) {
+	r, err := p.do(ctx, scanWifiRequest())
+	if err != nil {
 		return nil, err
 	}
 
-	if r.NetIf.GetScanInfo.ErrorCode != 0 {
-		return nil, fmt.Errorf("failed to scan for wifi networks. Error code=%d, msg: %s", r.NetIf.GetScanInfo.ErrorCode, r.NetIf.GetScanInfo.ErrorMessage)
-	}
-
 	return r.NetIf.GetScanInfo.List, nil
 }
 
 func (p *HS100) SetWifi(ssid string, password string, keyType int) error {
-	cmd := fmt.Sprintf(SET_WIFI, ssid, password, keyType)
-	data, err := exec(p.ip, cmd, p.timeout)
-	if err != nil {
-		return err
-	}
-
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
-
-	if r.NetIf.SetWifi.ErrorCode != 0 {
-		return fmt.Errorf("failed to set wifi. Error code=%d, msg: %s", r.NetIf.SetWifi.ErrorCode, r.NetIf.SetWifi.ErrorMessage)
-	}
+	return p.SetWifiContext(context.Background(), ssid, password, keyType)
+}
 
-	return nil
+func (p *HS100) SetWifiContext(ctx context.Context, ssid string, password string, keyType int) error {
+	req := setWifiRequest(ssid, password, keyType)
+	_, err := p.do(ctx, req)
+	return err
 }
 
 // Gets Cloud Info (Server, Username, Connection Status)
 func (p *HS100) CloudInfo() (*Cloud, error) {
-	data, err := exec(p.ip, GET_CLOUD_INFO, p.timeout)
-	if err != nil {
-		return nil, err
-	}
-
-	fmt.Println(data)
+	return p.CloudInfoContext(context.Background())
+}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+func (p *HS100) CloudInfoContext(ctx context.Context) (*Cloud, error) {
+	r, err := p.do(ctx, getCloudInfoRequest())
+	if err != nil {
 		return nil, err
 	}
 
-	if r.CNCloud.Info.ErrorCode != 0 {
-		return nil, fmt.Errorf("failed to get cloud info. Error code=%d, msg: %s", r.CNCloud.Info.ErrorCode, r.CNCloud.Info.ErrorMessage)
-	}
-
 	c := &Cloud{
 		Username: r.CNCloud.Info.Username,
 		Server:   r.CNCloud.Info.Server,
@@ -264,79 +210,47 @@ func (p *HS100) CloudInfo() (*Cloud, error) {
 
 // Set Server URL
 func (p *HS100) SetCloudUrl(url string) error {
-	cmd := fmt.Sprintf(SET_CLOUD_URL, url)
-	data, err := exec(p.ip, cmd, p.timeout)
-	if err != nil {
-		return err
-	}
-
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
-
-	if r.CNCloud.SetServerUrl.ErrorCode != 0 {
-		return fmt.Errorf("failed to get cloud info. Error code=%d, msg: %s", r.CNCloud.SetServerUrl.ErrorCode, r.CNCloud.SetServerUrl.ErrorMessage)
-	}
+	return p.SetCloudUrlContext(context.Background(), url)
+}
 
-	return nil
+func (p *HS100) SetCloudUrlContext(ctx context.Context, url string) error {
+	req := setCloudURLRequest(url)
+	_, err := p.do(ctx, req)
+	return err
 }
 
 // Connects with server using username & Password
 func (p *HS100) CloudBind(username string, password string) error {
-	cmd := fmt.Sprintf(CLOUD_BIND, username, password)
-	data, err := exec(p.ip, cmd, p.timeout)
-	if err != nil {
-		return err
-	}
-
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
-
-	if r.CNCloud.Bind.ErrorCode != 0 {
-		return fmt.Errorf("failed to bind to cloud. Error code=%d, msg: %s", r.CNCloud.Bind.ErrorCode, r.CNCloud.Bind.ErrorMessage)
-	}
+	return p.CloudBindContext(context.Background(), username, password)
+}
 
-	return nil
+func (p *HS100) CloudBindContext(ctx context.Context, username string, password string) error {
+	req := cloudBindRequest(username, password)
+	_, err := p.do(ctx, req)
+	return err
 }
 
 // Unregister Device from Cloud Account
 func (p *HS100) CloudUnbind() error {
-	data, err := exec(p.ip, CLOUD_UNBIND, p.timeout)
-	if err != nil {
-		return err
-	}
-
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
-
-	if r.CNCloud.Unbind.ErrorCode != 0 {
-		return fmt.Errorf("failed to unbind devide from cloud. Error code=%d, msg: %s", r.CNCloud.Unbind.ErrorCode, r.CNCloud.Unbind.ErrorMessage)
-	}
+	return p.CloudUnbindContext(context.Background())
+}
 
-	return nil
+func (p *HS100) CloudUnbindContext(ctx context.Context) error {
+	_, err := p.do(ctx, cloudUnbindRequest())
+	return err
 }
 
 // Gets Next Scheduled Action
 func (p *HS100) GetNextScheduledAction() (*NextAction, error) {
-	data, err := exec(p.ip, GET_NEXT_SCHEDULE_ACTION, p.timeout)
-	if err != nil {
-		return nil, err
-	}
+	return p.GetNextScheduledActionContext(context.Background())
+}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+func (p *HS100) GetNextScheduledActionContext(ctx context.Context) (*NextAction, error) {
+	r, err := p.do(ctx, getNextActionRequest())
+	if err != nil {
 		return nil, err
 	}
 
-	if r.Schedule.GetNextAction.ErrorCode != 0 {
-		return nil, fmt.Errorf("failed to get next scheduled action. Error code=%d, msg: %s", r.Schedule.GetNextAction.ErrorCode, r.Schedule.GetNextAction.ErrorMessage)
-	}
-
 	resp := &NextAction{
 		RuleID:              r.Schedule.GetNextAction.RuleID,
 		ScheduledTimeSecond: r.Schedule.GetNextAction.ScheduledTimeSecond,
@@ -347,127 +261,98 @@ func (p *HS100) GetNextScheduledAction() (*NextAction, error) {
 }
 
 // Gets Schedule Rules List
-func (p *HS100) GetScheduleList() ([]Rule, error) {
-	data, err := exec(p.ip, GET_SCHEDULE_RULES_LIST, p.timeout)
-	if err != nil {
-		return nil, err
-	}
+func (p *HS100) GetScheduleList() ([]ScheduleRule, error) {
+	return p.GetScheduleListContext(context.Background())
+}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+func (p *HS100) GetScheduleListContext(ctx context.Context) ([]ScheduleRule, error) {
+	r, err := p.do(ctx, getRulesRequest())
+	if err != nil {
 		return nil, err
 	}
 
-	if r.Schedule.Rule.ErrorCode != 0 {
-		return nil, fmt.Errorf("failed to get scheduled rules from device. Error code=%d, msg: %s", r.Schedule.Rule.ErrorCode, r.Schedule.Rule.ErrorMessage)
+	rules := make([]ScheduleRule, len(r.Schedule.Rule.List))
+	for i, v := range r.Schedule.Rule.List {
+		rules[i] = v.ScheduleRule()
 	}
-
-	return r.Schedule.Rule.List, nil
+	return rules, nil
 }
 
 // Add New Schedule Rule
 func (p *HS100) AddScheduleRule(name string, days Days, action Action, minutes int, enable int, year int, month int, day int) (string, error) {
-	return p.addScheduleRule(NONE, name, days, action, minutes, enable, year, month, day)
+	return p.AddScheduleRuleContext(context.Background(), name, days, action, minutes, enable, year, month, day)
+}
+
+func (p *HS100) AddScheduleRuleContext(ctx context.Context, name string, days Days, action Action, minutes int, enable int, year int, month int, day int) (string, error) {
+	return p.addScheduleRule(ctx, NONE, name, days, action, minutes, enable, year, month, day)
 }
 
 func (p *HS100) AddSunSetScheduleRule(name string, days Days, action Action, enable int, year int, month int, day int) (string, error) {
-	return p.addScheduleRule(SUNSET, name, days, action, 0, enable, year, month, day)
+	return p.AddSunSetScheduleRuleContext(context.Background(), name, days, action, enable, year, month, day)
+}
+
+func (p *HS100) AddSunSetScheduleRuleContext(ctx context.Context, name string, days Days, action Action, enable int, year int, month int, day int) (string, error) {
+	return p.addScheduleRule(ctx, SUNSET, name, days, action, 0, enable, year, month, day)
 }
 
 func (p *HS100) AddSunRiseScheduleRule(name string, days Days, action Action, enable int, year int, month int, day int) (string, error) {
-	return p.addScheduleRule(SUNRISE, name, days, action, 0, enable, year, month, day)
+	return p.AddSunRiseScheduleRuleContext(context.Background(), name, days, action, enable, year, month, day)
 }
 
-func (p *HS100) addScheduleRule(timeOpt TimeOption, name string, days Days, action Action, minutes int, enable int, year int, month int, day int) (string, error) {
-	weekdays := days.String()
-	repeat := OFF
+func (p *HS100) AddSunRiseScheduleRuleContext(ctx context.Context, name string, days Days, action Action, enable int, year int, month int, day int) (string, error) {
+	return p.addScheduleRule(ctx, SUNRISE, name, days, action, 0, enable, year, month, day)
+}
 
-	if weekdays != "[0,0,0,0,0,0,0]" {
-		repeat = ON
-	}
-	cmd := fmt.Sprintf(ADD_SCHEDULE_RULE, timeOpt, weekdays, minutes, enable, repeat, name, month, action, year, day)
-	data, err := exec(p.ip, cmd, p.timeout)
-	if err != nil {
+func (p *HS100) addScheduleRule(ctx context.Context, timeOpt TimeOption, name string, days Days, action Action, minutes int, enable int, year int, month int, day int) (string, error) {
+	params := newRuleParams("", timeOpt, name, days, action, minutes, enable, year, month, day)
+	if err := p.sunParams(ctx, params); err != nil {
 		return "", err
 	}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+	r, err := p.do(ctx, addRuleRequest(params))
+	if err != nil {
 		return "", err
 	}
 
-	if r.Schedule.AddRule.ErrorCode != 0 {
-		return "", fmt.Errorf("failed to add scheduled rules. Error code=%d, msg: %s", r.Schedule.AddRule.ErrorCode, r.Schedule.AddRule.ErrorMessage)
-	}
-
 	return r.Schedule.AddRule.ID, nil
 }
 
-// Edit Schedule Rule with given ID
+// Edit Schedule Rule with given ID, removing its end if any. Use UpdateRule to keep or change it.
 func (p *HS100) EditScheduleRule(id string, timeOpt TimeOption, name string, days Days, action Action, minutes int, enable int, year int, month int, day int) error {
-	weekdays := days.String()
-	repeat := OFF
-
-	if weekdays != "[0,0,0,0,0,0,0]" {
-		repeat = ON
-	}
-	cmd := fmt.Sprintf(EDIT_SCHEDULE_RULE, timeOpt, weekdays, minutes, enable, repeat, id, name, month, action, year, day)
-	data, err := exec(p.ip, cmd, p.timeout)
-	if err != nil {
-		return err
-	}
+	return p.EditScheduleRuleContext(context.Background(), id, timeOpt, name, days, action, minutes, enable, year, month, day)
+}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+func (p *HS100) EditScheduleRuleContext(ctx context.Context, id string, timeOpt TimeOption, name string, days Days, action Action, minutes int, enable int, year int, month int, day int) error {
+	params := newRuleParams(id, timeOpt, name, days, action, minutes, enable, year, month, day)
+	if err := p.sunParams(ctx, params); err != nil {
 		return err
 	}
 
-	if r.Schedule.EditRule.ErrorCode != 0 {
-		return fmt.Errorf("failed to edit scheduled rules. Error code=%d, msg: %s", r.Schedule.EditRule.ErrorCode, r.Schedule.EditRule.ErrorMessage)
-	}
-
-	return nil
+	_, err := p.do(ctx, editRuleRequest(params))
+	return err
 }
 
 // Delete Schedule Rule with given ID
 func (p *HS100) DeleteScheduleRule(id string) error {
-	cmd := fmt.Sprintf(DELETE_SCHEDULE_RULE, id)
-	data, err := exec(p.ip, cmd, p.timeout)
-	if err != nil {
-		return err
-	}
-
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
-
-	if r.Schedule.DeleteRule.ErrorCode != 0 {
-		return fmt.Errorf("failed to edit scheduled rules. Error code=%d, msg: %s", r.Schedule.DeleteRule.ErrorCode, r.Schedule.DeleteRule.ErrorMessage)
-	}
+	return p.DeleteScheduleRuleContext(context.Background(), id)
+}
 
-	return nil
+func (p *HS100) DeleteScheduleRuleContext(ctx context.Context, id string) error {
+	req := deleteRuleRequest(id)
+	_, err := p.do(ctx, req)
+	return err
 }
 
 // Delete All Schedule Rules and Erase Statistics
 func (p *HS100) DeleteAllScheduleRule() error {
-	data, err := exec(p.ip, DELETE_ALL_SCHEDULE_RULE, p.timeout)
-	if err != nil {
-		return err
-	}
-
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
-		return err
-	}
-
-	if r.Schedule.DeleteAllRules.ErrorCode != 0 {
-		return fmt.Errorf("failed to edit scheduled rules. Error code=%d, msg: %s", r.Schedule.DeleteAllRules.ErrorCode, r.Schedule.DeleteAllRules.ErrorMessage)
-	}
+	return p.DeleteAllScheduleRuleContext(context.Background())
+}
 
-	return nil
+func (p *HS100) DeleteAllScheduleRuleContext(ctx context.Context) error {
+	_, err := p.do(ctx, deleteAllRulesRequest())
+	return err
 }
 
-func NewHS100(ip string, timeout time.Duration) *HS100 {
-	return &HS100{ip: ip, timeout: timeout}
+func NewHS100(ip string, timeout time.Duration, opts ...Option) *HS100 {
+	return &HS100{newClient(ip, timeout, opts...)}
 }
@@ -8,6 +8,6 @@ type HS105 struct {
 	HS100
 }
 
-func NewHS105(ip string, timeout time.Duration) *HS100 {
-	return &HS100{ip: ip, timeout: timeout}
+func NewHS105(ip string, timeout time.Duration, opts ...Option) *HS105 {
+	return &HS105{HS100{newClient(ip, timeout, opts...)}}
 }
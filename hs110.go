@@ -1,8 +1,7 @@
 package tplink
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"time"
 )
 
@@ -13,68 +12,131 @@ type HS110 struct {
 
 // Gets Realtime Current and Voltage Reading
 func (p *HS110) Meter() (*Meter, error) {
-	data, err := exec(p.ip, GET_METER, p.timeout)
-	if err != nil {
+	return p.MeterContext(context.Background())
+}
+
+func (p *HS110) MeterContext(ctx context.Context) (*Meter, error) {
+	if err := p.require(ENERGY_METER); err != nil {
 		return nil, err
 	}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+	r, err := p.do(ctx, getMeterRequest())
+	if err != nil {
 		return nil, err
 	}
 
+	if r.EMeter.Meter == nil {
+		return nil, ErrUnsupported
+	}
 	return r.EMeter.Meter, nil
 }
 
 // Gets Daily Statistic for given Month
 func (p *HS110) DailyStats(month int, year int) ([]*DailyUsage, error) {
-	data, err := exec(p.ip, fmt.Sprintf(GET_DAILY_STATS, month, year), p.timeout)
-	if err != nil {
+	return p.DailyStatsContext(context.Background(), month, year)
+}
+
+func (p *HS110) DailyStatsContext(ctx context.Context, month int, year int) ([]*DailyUsage, error) {
+	if err := p.require(ENERGY_METER); err != nil {
 		return nil, err
 	}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+	r, err := p.do(ctx, getDailyStatsRequest(month, year))
+	if err != nil {
 		return nil, err
 	}
 
+	if r.EMeter.DailyStats == nil {
+		return nil, ErrUnsupported
+	}
 	return r.EMeter.DailyStats.DailyUsageList, nil
 }
 
 // Get Montly Statistic for given Year
 func (p *HS110) MonthlyStats(year int) ([]*MonthlyUsage, error) {
-	data, err := exec(p.ip, fmt.Sprintf(GET_MONTHLY_STATS, year), p.timeout)
-	if err != nil {
+	return p.MonthlyStatsContext(context.Background(), year)
+}
+
+func (p *HS110) MonthlyStatsContext(ctx context.Context, year int) ([]*MonthlyUsage, error) {
+	if err := p.require(ENERGY_METER); err != nil {
 		return nil, err
 	}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+	r, err := p.do(ctx, getMonthlyStatsRequest(year))
+	if err != nil {
 		return nil, err
 	}
 
+	if r.EMeter.MonthlyStats == nil {
+		return nil, ErrUnsupported
+	}
 	return r.EMeter.MonthlyStats.MonthlyUsageList, nil
 }
 
 // Erase All EMeter Statistics
 func (p *HS110) EraseAllStats() error {
-	data, err := exec(p.ip, ERASE_ALL_STATS, p.timeout)
-	if err != nil {
+	return p.EraseAllStatsContext(context.Background())
+}
+
+func (p *HS110) EraseAllStatsContext(ctx context.Context) error {
+	if err := p.require(ENERGY_METER); err != nil {
 		return err
 	}
 
-	r := Response{}
-	if err := json.Unmarshal([]byte(data), &r); err != nil {
+	_, err := p.do(ctx, eraseAllStatsRequest())
+	return err
+}
+
+// Gets the voltage and current gains used to calibrate the energy meter
+func (p *HS110) VGainIGain() (int, int, error) {
+	return p.VGainIGainContext(context.Background())
+}
+
+func (p *HS110) VGainIGainContext(ctx context.Context) (int, int, error) {
+	if err := p.require(ENERGY_METER); err != nil {
+		return 0, 0, err
+	}
+
+	r, err := p.do(ctx, getVGainIGainRequest())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if r.EMeter.VGainIGain == nil {
+		return 0, 0, ErrUnsupported
+	}
+	return r.EMeter.VGainIGain.VGain, r.EMeter.VGainIGain.IGain, nil
+}
+
+// Sets the voltage and current gains of the energy meter
+func (p *HS110) SetVGainIGain(vgain int, igain int) error {
+	return p.SetVGainIGainContext(context.Background(), vgain, igain)
+}
+
+func (p *HS110) SetVGainIGainContext(ctx context.Context, vgain int, igain int) error {
+	if err := p.require(ENERGY_METER); err != nil {
 		return err
 	}
 
-	if r.EMeter.EraseMeterStat.ErrorCode != 0 {
-		return fmt.Errorf("failed to erase meter stats. Error code=%d, msg: %s", r.EMeter.EraseMeterStat.ErrorCode, r.EMeter.EraseMeterStat.ErrorMessage)
+	_, err := p.do(ctx, setVGainIGainRequest(vgain, igain))
+	return err
+}
+
+// Starts the energy meter calibration against the given voltage and current targets,
+// as measured by a reference meter on the same load
+func (p *HS110) StartCalibration(vtarget int, itarget int) error {
+	return p.StartCalibrationContext(context.Background(), vtarget, itarget)
+}
+
+func (p *HS110) StartCalibrationContext(ctx context.Context, vtarget int, itarget int) error {
+	if err := p.require(ENERGY_METER); err != nil {
+		return err
 	}
 
-	return nil
+	_, err := p.do(ctx, startCalibrationRequest(vtarget, itarget))
+	return err
 }
 
-func NewHS110(ip string, timeout time.Duration) *HS110 {
-	return &HS110{HS100{ip: ip, timeout: timeout}}
+func NewHS110(ip string, timeout time.Duration, opts ...Option) *HS110 {
+	return &HS110{HS100{newClient(ip, timeout, opts...)}}
 }
@@ -0,0 +1,115 @@
+package tplink
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMeterFirmwareGenerations(t *testing.T) {
+	for _, fixture := range []string{"testdata/emeter_v1.json", "testdata/emeter_v2.json"} {
+		data, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		replies := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &replies); err != nil {
+			t.Fatal(err)
+		}
+
+		f := &fakeTransport{replies: map[string]string{
+			`{"emeter":{"get_realtime":{}}}`:                     string(replies["realtime"]),
+			`{"emeter":{"get_daystat":{"month":3,"year":2018}}}`: string(replies["daystat"]),
+			`{"emeter":{"get_monthstat":{"year":2018}}}`:         string(replies["monthstat"]),
+		}}
+		plug := NewHS110("10.0.0.1", time.Second, WithTransport(f))
+
+		m, err := plug.Meter()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expecting := Meter{Current: 0.512, Voltage: 120.5, Power: 61.2, Total: 12.345}
+		if !closeTo(m.Current, expecting.Current) || !closeTo(m.Voltage, expecting.Voltage) ||
+			!closeTo(m.Power, expecting.Power) || !closeTo(m.Total, expecting.Total) {
+			t.Errorf("%s: expecting %+v; got %+v", fixture, expecting, *m)
+		}
+
+		days, err := plug.DailyStats(3, 2018)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(days) != 2 || days[1].Day != 2 || !closeTo(days[0].Energy, 1.25) || !closeTo(days[1].Energy, 0.75) {
+			t.Errorf("%s: unexpected daily stats %+v %+v", fixture, days[0], days[1])
+		}
+
+		months, err := plug.MonthlyStats(2018)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(months) != 1 || months[0].Month != 3 || !closeTo(months[0].Energy, 2) {
+			t.Errorf("%s: unexpected monthly stats %+v", fixture, months[0])
+		}
+	}
+}
+
+// Some firmwares reject get_vgain_igain, the meter must still be readable
+func TestMeterWithoutGains(t *testing.T) {
+	data, err := os.ReadFile("testdata/emeter_no_vgain.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replies := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &replies); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeTransport{replies: map[string]string{
+		`{"emeter":{"get_realtime":{}}}`:    string(replies["realtime"]),
+		`{"emeter":{"get_vgain_igain":{}}}`: string(replies["vgain"]),
+	}}
+	plug := NewHS110("10.0.0.1", time.Second, WithTransport(f))
+
+	m, err := plug.Meter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closeTo(m.Power, 12.4) {
+		t.Errorf("expecting 12.4W; got %v", m.Power)
+	}
+
+	if _, _, err := plug.VGainIGain(); !errors.Is(err, ErrMethodNotSupported) {
+		t.Errorf("expecting %v; got %v", ErrMethodNotSupported, err)
+	}
+}
+
+func closeTo(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCalibration(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		`{"emeter":{"get_vgain_igain":{}}}`:                            `{"emeter":{"get_vgain_igain":{"vgain":13462,"igain":16835,"err_code":0}}}`,
+		`{"emeter":{"set_vgain_igain":{"vgain":13500,"igain":16800}}}`: `{"emeter":{"set_vgain_igain":{"err_code":0}}}`,
+		`{"emeter":{"start_calibration":{"vtarget":0,"itarget":0}}}`:   `{"emeter":{"start_calibration":{"err_code":-3,"err_msg":"invalid argument"}}}`,
+	}}
+	plug := NewHS110("10.0.0.1", time.Second, WithTransport(f))
+
+	vgain, igain, err := plug.VGainIGain()
+	if err != nil || vgain != 13462 || igain != 16835 {
+		t.Errorf("expecting 13462, 16835; got %d, %d, %v", vgain, igain, err)
+	}
+
+	if err := plug.SetVGainIGain(13500, 16800); err != nil {
+		t.Error(err)
+	}
+
+	if err := plug.StartCalibration(0, 0); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expecting %v; got %v", ErrInvalidArgument, err)
+	}
+}
@@ -0,0 +1,145 @@
+package tplink
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// What a dimmer does on a double click or a long press of its button
+type GestureAction string
+
+const (
+	GESTURE_NONE             GestureAction = "none"
+	GESTURE_INSTANT_ON_OFF   GestureAction = "instant_on_off"
+	GESTURE_GENTLE_ON_OFF    GestureAction = "gentle_on_off"
+	GESTURE_CUSTOMIZE_PRESET GestureAction = "customize_preset"
+)
+
+// Dimmer settings, durations are in milliseconds
+type DimmerParameters struct {
+	MinThreshold  int `json:"minThreshold"`
+	FadeOnTime    int `json:"fadeOnTime"`
+	FadeOffTime   int `json:"fadeOffTime"`
+	GentleOnTime  int `json:"gentleOnTime"`
+	GentleOffTime int `json:"gentleOffTime"`
+	RampRate      int `json:"rampRate"`
+	BulbType      int `json:"bulb_type"`
+}
+
+// TP-Link HS220 dimmer switch, also ES20M
+type HS220 struct {
+	HS100
+}
+
+func validBrightness(level int) error {
+	if level < 1 || level > 100 {
+		return fmt.Errorf("invalid brightness %d, must be between 1 and 100", level)
+	}
+	return nil
+}
+
+// Gets the current brightness, 1 to 100
+func (p *HS220) Brightness() (int, error) {
+	return p.BrightnessContext(context.Background())
+}
+
+func (p *HS220) BrightnessContext(ctx context.Context) (int, error) {
+	info, err := p.InfoContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if info == nil {
+		return 0, ErrUnsupported
+	}
+	return info.Brightness, nil
+}
+
+// Sets the brightness, 1 to 100
+func (p *HS220) SetBrightness(level int) error {
+	return p.SetBrightnessContext(context.Background(), level)
+}
+
+func (p *HS220) SetBrightnessContext(ctx context.Context, level int) error {
+	if err := validBrightness(level); err != nil {
+		return err
+	}
+
+	_, err := p.do(ctx, setBrightnessRequest(level))
+	return err
+}
+
+// Gradually changes the brightness to level over duration
+func (p *HS220) SetTransition(level int, duration time.Duration) error {
+	return p.SetTransitionContext(context.Background(), level, duration)
+}
+
+func (p *HS220) SetTransitionContext(ctx context.Context, level int, duration time.Duration) error {
+	if err := validBrightness(level); err != nil {
+		return err
+	}
+
+	_, err := p.do(ctx, setDimmerTransitionRequest(level, duration))
+	return err
+}
+
+// Gets the dimmer settings
+func (p *HS220) Parameters() (*DimmerParameters, error) {
+	return p.ParametersContext(context.Background())
+}
+
+func (p *HS220) ParametersContext(ctx context.Context) (*DimmerParameters, error) {
+	r, err := p.do(ctx, getDimmerParametersRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Dimmer.Parameters == nil {
+		return nil, ErrUnsupported
+	}
+	return r.Dimmer.Parameters, nil
+}
+
+// Sets what a double click on the button does
+func (p *HS220) SetDoubleClickAction(action GestureAction) error {
+	return p.SetDoubleClickActionContext(context.Background(), action)
+}
+
+func (p *HS220) SetDoubleClickActionContext(ctx context.Context, action GestureAction) error {
+	_, err := p.do(ctx, setDoubleClickActionRequest(action))
+	return err
+}
+
+// Sets what a long press on the button does
+func (p *HS220) SetLongPressAction(action GestureAction) error {
+	return p.SetLongPressActionContext(context.Background(), action)
+}
+
+func (p *HS220) SetLongPressActionContext(ctx context.Context, action GestureAction) error {
+	_, err := p.do(ctx, setLongPressActionRequest(action))
+	return err
+}
+
+// Sets how long the light takes to fade in when turned on
+func (p *HS220) SetFadeOnTime(d time.Duration) error {
+	return p.SetFadeOnTimeContext(context.Background(), d)
+}
+
+func (p *HS220) SetFadeOnTimeContext(ctx context.Context, d time.Duration) error {
+	_, err := p.do(ctx, setFadeOnTimeRequest(d))
+	return err
+}
+
+// Sets how long the light takes to fade out when turned off
+func (p *HS220) SetFadeOffTime(d time.Duration) error {
+	return p.SetFadeOffTimeContext(context.Background(), d)
+}
+
+func (p *HS220) SetFadeOffTimeContext(ctx context.Context, d time.Duration) error {
+	_, err := p.do(ctx, setFadeOffTimeRequest(d))
+	return err
+}
+
+func NewHS220(ip string, timeout time.Duration, opts ...Option) *HS220 {
+	return &HS220{HS100{newClient(ip, timeout, opts...)}}
+}
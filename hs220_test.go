@@ -0,0 +1,51 @@
+package tplink
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHS220(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		GET_INFO: `{"system":{"get_sysinfo":{"model":"HS220(US)","type":"IOT.SMARTPLUGSWITCH","feature":"TIM","brightness":35,"err_code":0}}}`,
+		`{"smartlife.iot.dimmer":{"set_brightness":{"brightness":80}}}`:                        `{"smartlife.iot.dimmer":{"set_brightness":{"err_code":0}}}`,
+		`{"smartlife.iot.dimmer":{"set_dimmer_transition":{"brightness":10,"duration":1500}}}`: `{"smartlife.iot.dimmer":{"set_dimmer_transition":{"err_code":0}}}`,
+		`{"smartlife.iot.dimmer":{"set_double_click_action":{"mode":"gentle_on_off"}}}`:        `{"smartlife.iot.dimmer":{"set_double_click_action":{"err_code":0}}}`,
+	}}
+
+	d, err := Connect(context.Background(), "10.0.0.1", WithTransport(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dimmer, ok := d.(Dimmer)
+	if !ok {
+		t.Fatalf("expecting a Dimmer; got %T", d)
+	}
+
+	if level, err := dimmer.Brightness(); err != nil || level != 35 {
+		t.Errorf("expecting 35; got %d, %v", level, err)
+	}
+
+	if err := dimmer.SetBrightness(80); err != nil {
+		t.Error(err)
+	}
+
+	hs220 := d.(*HS220)
+	if err := hs220.SetTransition(10, 1500*time.Millisecond); err != nil {
+		t.Error(err)
+	}
+
+	if err := hs220.SetDoubleClickAction(GESTURE_GENTLE_ON_OFF); err != nil {
+		t.Error(err)
+	}
+
+	f.sent = nil
+	if err := dimmer.SetBrightness(0); err == nil {
+		t.Error("expecting an error for brightness 0")
+	}
+	if len(f.sent) != 0 {
+		t.Errorf("expecting no request; got %v", f.sent)
+	}
+}
@@ -0,0 +1,111 @@
+package tplink
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TP-Link HS300 power strip. Commands sent to the strip apply to all of its outlets,
+// use Outlet to address a single one.
+type HS300 struct {
+	HS100
+	deviceID string // known once the strip info was read, completes outlet indexes
+}
+
+// An outlet of a HS300 power strip. Every command is sent with the outlet child ID,
+// so relay, alias, schedule and energy meter methods apply to that outlet only.
+type HS300Outlet struct {
+	HS110
+	ID string
+}
+
+// fullChildID completes the outlet index that some firmwares report instead of the child ID
+func fullChildID(deviceID string, id string) string {
+	if len(id) <= 2 {
+		return deviceID + id
+	}
+	return id
+}
+
+// Returns the outlet with the given child ID, as found in Children. An outlet index, e.g. "01",
+// is completed with the strip device ID once known, from Connect or Children.
+func (p *HS300) Outlet(id string) *HS300Outlet {
+	id = fullChildID(p.deviceID, id)
+	c := p.client
+	c.childIDs = []string{id}
+	return &HS300Outlet{HS110: HS110{HS100{c}}, ID: id}
+}
+
+// Gets the strip info, with the alias and relay state of the outlet
+func (p *HS300Outlet) Info() (*Info, error) {
+	return p.InfoContext(context.Background())
+}
+
+func (p *HS300Outlet) InfoContext(ctx context.Context) (*Info, error) {
+	// sysinfo is only available for the whole strip
+	strip := HS100{p.client}
+	strip.childIDs = nil
+	info, err := strip.InfoContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, ErrUnsupported
+	}
+
+	id := fullChildID(info.DeviceID, p.ID)
+	for _, child := range info.Children {
+		if fullChildID(info.DeviceID, child.ID) == id {
+			info.Alias = child.Alias
+			info.State = child.State
+			return info, nil
+		}
+	}
+	return nil, fmt.Errorf("outlet %s not found", p.ID)
+}
+
+// Gets the outlets of the strip, with their full child ID
+func (p *HS300) Children() ([]Child, error) {
+	return p.ChildrenContext(context.Background())
+}
+
+func (p *HS300) ChildrenContext(ctx context.Context) ([]Child, error) {
+	info, err := p.InfoContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, ErrUnsupported
+	}
+
+	p.deviceID = info.DeviceID
+	children := make([]Child, len(info.Children))
+	for i, child := range info.Children {
+		child.ID = fullChildID(info.DeviceID, child.ID)
+		children[i] = child
+	}
+	return children, nil
+}
+
+// Gets all the outlets of the strip
+func (p *HS300) Outlets() ([]Device, error) {
+	return p.OutletsContext(context.Background())
+}
+
+func (p *HS300) OutletsContext(ctx context.Context) ([]Device, error) {
+	children, err := p.ChildrenContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outlets := make([]Device, len(children))
+	for i, child := range children {
+		outlets[i] = p.Outlet(child.ID)
+	}
+	return outlets, nil
+}
+
+func NewHS300(ip string, timeout time.Duration, opts ...Option) *HS300 {
+	return &HS300{HS100: HS100{newClient(ip, timeout, opts...)}}
+}
@@ -0,0 +1,115 @@
+package tplink
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHS300(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		GET_INFO: `{"system":{"get_sysinfo":{"model":"HS300(US)","type":"IOT.SMARTPLUGSWITCH","feature":"TIM:ENE","deviceId":"8006ABCD","child_num":2,"children":[
+			{"id":"8006ABCD00","state":1,"alias":"Lamp","on_time":120},
+			{"id":"01","state":0,"alias":"Heater","on_time":0}
+		],"err_code":0}}}`,
+		`{"context":{"child_ids":["8006ABCD01"]},"system":{"set_relay_state":{"state":1}}}`: `{"system":{"set_relay_state":{"err_code":0}}}`,
+		`{"context":{"child_ids":["8006ABCD00"]},"emeter":{"get_realtime":{}}}`:             `{"emeter":{"get_realtime":{"power":42,"err_code":0}}}`,
+	}}
+
+	d, err := Connect(context.Background(), "10.0.0.1", WithTransport(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strip, ok := d.(*HS300)
+	if !ok {
+		t.Fatalf("expecting *HS300; got %T", d)
+	}
+
+	children, err := strip.Children()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(children) != 2 || children[0].ID != "8006ABCD00" || children[1].ID != "8006ABCD01" || !children[0].IsOn() {
+		t.Errorf("unexpected children: %+v", children)
+	}
+
+	if err := strip.Outlet(children[1].ID).TurnOn(); err != nil {
+		t.Error(err)
+	}
+
+	// an outlet index is sent as the full child ID
+	f.sent = nil
+	if err := strip.Outlet("01").TurnOn(); err != nil {
+		t.Error(err)
+	}
+	if len(f.sent) != 1 || !strings.Contains(f.sent[0], `"child_ids":["8006ABCD01"]`) {
+		t.Errorf("expecting the full child ID; got %v", f.sent)
+	}
+	if info, err := strip.Outlet("00").Info(); err != nil || info.Alias != "Lamp" {
+		t.Errorf("expecting Lamp; got %v, %v", info, err)
+	}
+
+	outlets, err := strip.Outlets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meter, err := outlets[0].(EnergyMeter).Meter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meter.Power != 42 {
+		t.Errorf("expecting 42W; got %v", meter.Power)
+	}
+
+	for i, expecting := range []struct {
+		alias string
+		on    bool
+	}{{"Lamp", true}, {"Heater", false}} {
+		info, err := outlets[i].Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Alias != expecting.alias || info.IsOn() != expecting.on {
+			t.Errorf("outlet %d: expecting %s on=%v; got %s on=%v", i, expecting.alias, expecting.on, info.Alias, info.IsOn())
+		}
+	}
+}
+
+func TestHS300OutletInfo(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		GET_INFO: `{"system":{"get_sysinfo":{"alias":"Strip","deviceId":"X","children":[{"id":"X00","state":0,"alias":"Lamp"},{"id":"X01","state":1,"alias":"Heater"}],"err_code":0}}}`,
+	}}
+	strip := NewHS300("10.0.0.1", time.Second, WithTransport(f))
+
+	info, err := strip.Outlet("X01").Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Alias != "Heater" || !info.IsOn() {
+		t.Errorf("expecting Heater on; got %s on=%v", info.Alias, info.IsOn())
+	}
+
+	if _, err := strip.Outlet("X02").Info(); err == nil {
+		t.Error("expecting an error for an unknown outlet")
+	}
+}
+
+func TestHS300Query(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		`{"context":{"child_ids":["X01"]},"count_down":{"get_rules":null}}`: `{"count_down":{"get_rules":{"rule_list":[],"err_code":0}}}`,
+	}}
+
+	outlet := NewHS300("10.0.0.1", time.Second, WithTransport(f)).Outlet("X01")
+	r, err := outlet.Query(map[string]map[string]interface{}{"count_down": {"get_rules": nil}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := r["count_down"]["get_rules"]; !ok {
+		t.Errorf("unexpected reply: %v", r)
+	}
+}
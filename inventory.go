@@ -0,0 +1,182 @@
+package tplink
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Minimum software version per model, e.g. {"HS110(US)": "1.2.6"}.
+// Keys are looked up as "HS110(US)/1.0" (model and hardware version), "HS110(US)" then "HS110".
+type VersionPolicy map[string]string
+
+func (p VersionPolicy) lookup(model string, hwVersion string) string {
+	keys := []string{model + "/" + hwVersion, model}
+	if i := strings.IndexByte(model, '('); i > 0 {
+		keys = append(keys, model[:i])
+	}
+
+	for _, k := range keys {
+		if v, ok := p[k]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+type InventoryDevice struct {
+	IPAddress       string `json:"ip_address"`
+	Alias           string `json:"alias"`
+	DeviceID        string `json:"device_id"`
+	SoftwareVersion string `json:"sw_ver"`
+	MinVersion      string `json:"min_ver,omitempty"` // empty when the policy has no entry for the model
+	Outdated        bool   `json:"outdated"`
+	Updating        bool   `json:"updating"`
+}
+
+// Devices sharing a model and hardware revision
+type InventoryGroup struct {
+	Model           string            `json:"model"`
+	HardwareVersion string            `json:"hw_ver"`
+	Devices         []InventoryDevice `json:"devices"`
+}
+
+type Inventory struct {
+	Groups []InventoryGroup `json:"groups"`
+}
+
+// Groups devices found by Scan by model and hardware revision, flagging the ones below the policy or being updated
+func NewInventory(devices []ScanResult, policy VersionPolicy) *Inventory {
+	index := map[[2]string]int{}
+	inv := &Inventory{}
+
+	for _, d := range devices {
+		key := [2]string{d.Info.Model, d.Info.HardwareVersion}
+		i, ok := index[key]
+		if !ok {
+			i = len(inv.Groups)
+			index[key] = i
+			inv.Groups = append(inv.Groups, InventoryGroup{Model: d.Info.Model, HardwareVersion: d.Info.HardwareVersion})
+		}
+
+		minVersion := policy.lookup(d.Info.Model, d.Info.HardwareVersion)
+		inv.Groups[i].Devices = append(inv.Groups[i].Devices, InventoryDevice{
+			IPAddress:       d.IPAddress,
+			Alias:           d.Info.Alias,
+			DeviceID:        d.Info.DeviceID,
+			SoftwareVersion: d.Info.SoftwareVersion,
+			MinVersion:      minVersion,
+			Outdated:        minVersion != "" && compareVersions(d.Info.SoftwareVersion, minVersion) < 0,
+			Updating:        d.Info.Updating != 0,
+		})
+	}
+
+	sort.Slice(inv.Groups, func(i, j int) bool {
+		a, b := inv.Groups[i], inv.Groups[j]
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return compareVersions(a.HardwareVersion, b.HardwareVersion) < 0
+	})
+	for _, g := range inv.Groups {
+		sort.Slice(g.Devices, func(i, j int) bool {
+			return lessAddr(g.Devices[i].IPAddress, g.Devices[j].IPAddress)
+		})
+	}
+	return inv
+}
+
+// Devices below the policy or being updated
+func (inv *Inventory) NonCompliant() []InventoryDevice {
+	var devices []InventoryDevice
+	for _, g := range inv.Groups {
+		for _, d := range g.Devices {
+			if d.Outdated || d.Updating {
+				devices = append(devices, d)
+			}
+		}
+	}
+	return devices
+}
+
+func (inv *Inventory) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(inv)
+}
+
+// One line per device
+func (inv *Inventory) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"model", "hw_ver", "ip_address", "alias", "device_id", "sw_ver", "min_ver", "outdated", "updating"})
+	for _, g := range inv.Groups {
+		for _, d := range g.Devices {
+			cw.Write([]string{
+				g.Model,
+				g.HardwareVersion,
+				d.IPAddress,
+				d.Alias,
+				d.DeviceID,
+				d.SoftwareVersion,
+				d.MinVersion,
+				strconv.FormatBool(d.Outdated),
+				strconv.FormatBool(d.Updating),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// lessAddr orders IP addresses numerically, so that 10.0.1.9 comes before 10.0.1.10
+func lessAddr(a string, b string) bool {
+	x, errX := netip.ParseAddr(a)
+	y, errY := netip.ParseAddr(b)
+	if errX != nil || errY != nil {
+		return a < b
+	}
+	return x.Less(y)
+}
+
+// compareVersions compares the leading dotted numbers of two versions,
+// "1.5.1 Build 171109 Rel.165709" being read as 1.5.1. Missing parts count as 0.
+func compareVersions(a string, b string) int {
+	va, vb := versionParts(a), versionParts(b)
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	if i := strings.IndexByte(v, ' '); i >= 0 {
+		v = v[:i]
+	}
+
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,90 @@
+package tplink
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tt := []struct {
+		a, b      string
+		expecting int
+	}{
+		{"1.5.1 Build 171109 Rel.165709", "1.5.1", 0},
+		{"1.2.5 Build 171206 Rel.085954", "1.2.6", -1},
+		{"1.10.0", "1.9.9", 1},
+		{"2.0", "2.0.0", 0},
+		{"1.0.2", "1.0", 1},
+	}
+
+	for _, v := range tt {
+		if c := compareVersions(v.a, v.b); c != v.expecting {
+			t.Errorf("%s vs %s: expecting %d; got %d", v.a, v.b, v.expecting, c)
+		}
+	}
+}
+
+func TestInventory(t *testing.T) {
+	devices := []ScanResult{
+		{IPAddress: "10.0.1.3", Info: Info{Model: "HS110(US)", HardwareVersion: "1.0", SoftwareVersion: "1.2.5 Build 171206 Rel.085954", Alias: "Plug3"}},
+		{IPAddress: "10.0.1.10", Info: Info{Model: "HS110(US)", HardwareVersion: "1.0", SoftwareVersion: "1.2.6 Build 200727 Rel.121701", Alias: "Plug10"}},
+		{IPAddress: "10.0.1.1", Info: Info{Model: "HS110(US)", HardwareVersion: "1.0", SoftwareVersion: "1.2.6 Build 200727 Rel.121701", Alias: "Plug1"}},
+		{IPAddress: "10.0.1.2", Info: Info{Model: "HS100(US)", HardwareVersion: "2.0", SoftwareVersion: "1.5.1 Build 171109 Rel.165709", Alias: "Plug2", Updating: 1}},
+		{IPAddress: "10.0.1.4", Info: Info{Model: "HS110(US)", HardwareVersion: "4.0", SoftwareVersion: "1.0.4 Build 191111 Rel.143500", Alias: "Plug4"}},
+		{IPAddress: "10.0.1.5", Info: Info{Model: "KL130(US)", HardwareVersion: "1.0", SoftwareVersion: "1.8.8", Alias: "Bulb"}},
+	}
+	policy := VersionPolicy{
+		"HS110":         "1.2.6",
+		"HS110(US)/4.0": "1.0.4",
+		"HS100(US)":     "1.5.1",
+	}
+
+	inv := NewInventory(devices, policy)
+
+	var groups []string
+	for _, g := range inv.Groups {
+		groups = append(groups, g.Model+"/"+g.HardwareVersion)
+	}
+	if strings.Join(groups, " ") != "HS100(US)/2.0 HS110(US)/1.0 HS110(US)/4.0 KL130(US)/1.0" {
+		t.Errorf("unexpected groups %v", groups)
+	}
+
+	hs110 := inv.Groups[1].Devices
+	if len(hs110) != 3 || hs110[0].IPAddress != "10.0.1.1" || hs110[2].IPAddress != "10.0.1.10" || hs110[0].Outdated || !hs110[1].Outdated {
+		t.Errorf("unexpected HS110 devices %+v", hs110)
+	}
+	if inv.Groups[2].Devices[0].Outdated || inv.Groups[3].Devices[0].MinVersion != "" {
+		t.Errorf("unexpected groups %+v", inv.Groups)
+	}
+
+	var aliases []string
+	for _, d := range inv.NonCompliant() {
+		aliases = append(aliases, d.Alias)
+	}
+	if strings.Join(aliases, " ") != "Plug2 Plug3" {
+		t.Errorf("unexpected non compliant devices %v", aliases)
+	}
+
+	var buf bytes.Buffer
+	if err := inv.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 7 || lines[3] != "HS110(US),1.0,10.0.1.3,Plug3,,1.2.5 Build 171206 Rel.085954,1.2.6,true,false" {
+		t.Errorf("unexpected CSV %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := inv.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Inventory
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Groups) != 4 || !decoded.Groups[0].Devices[0].Updating {
+		t.Errorf("unexpected JSON %s", buf.String())
+	}
+}
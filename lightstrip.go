@@ -0,0 +1,159 @@
+package tplink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Color of a range of segments of a light strip, from Start to End included
+type SegmentColor struct {
+	Start      int
+	End        int
+	Hue        int // 0 to 360
+	Saturation int // 0 to 100
+	Brightness int // 1 to 100
+	ColorTemp  int // Kelvin, 0 to use the color
+}
+
+// Segment colors are sent as [start, end, hue, saturation, brightness, color_temp]
+func (s SegmentColor) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]int{s.Start, s.End, s.Hue, s.Saturation, s.Brightness, s.ColorTemp})
+}
+
+func (s *SegmentColor) UnmarshalJSON(data []byte) error {
+	v := []int{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if len(v) < 5 {
+		return fmt.Errorf("invalid segment color %s", data)
+	}
+
+	*s = SegmentColor{Start: v[0], End: v[1], Hue: v[2], Saturation: v[3], Brightness: v[4]}
+	if len(v) > 5 {
+		s.ColorTemp = v[5]
+	}
+	return nil
+}
+
+// A lighting effect of a light strip, either built-in or custom
+type LightingEffect struct {
+	ID                string  `json:"id"`
+	Name              string  `json:"name"`
+	Custom            int     `json:"custom"` // 1 for an uploaded effect
+	Enable            int     `json:"enable"`
+	Brightness        int     `json:"brightness"`
+	Type              string  `json:"type"`                       // e.g. "sequence", "random", "pulse"
+	Duration          int     `json:"duration"`                   // milliseconds, 0 = forever
+	Transition        int     `json:"transition"`                 // milliseconds
+	Direction         int     `json:"direction"`                  // 1 = forward; 4 = backward, sequence effects only
+	Spread            int     `json:"spread"`                     // sequence effects only
+	RepeatTimes       int     `json:"repeat_times"`               // 0 = forever
+	Segments          []int   `json:"segments"`                   // segments the effect applies to
+	ExpansionStrategy int     `json:"expansion_strategy"`         // 1 = segments are expanded to the whole strip
+	Sequence          [][]int `json:"sequence,omitempty"`         // [hue, saturation, brightness] steps, sequence effects only
+	HueRange          []int   `json:"hue_range,omitempty"`        // [min, max], random effects only
+	SaturationRange   []int   `json:"saturation_range,omitempty"` // [min, max], random effects only
+	BrightnessRange   []int   `json:"brightness_range,omitempty"` // [min, max], random effects only
+}
+
+// State of the lighting effect reported in the sysinfo
+type LightingEffectState struct {
+	Enable     int    `json:"enable"`
+	Name       string `json:"name"`
+	ID         string `json:"id"`
+	Custom     int    `json:"custom"`
+	Brightness int    `json:"brightness"`
+}
+
+// System information of a light strip
+type LightStripInfo struct {
+	BulbInfo
+	Length              int                  `json:"length"` // Number of segments
+	LightingEffectState *LightingEffectState `json:"lighting_effect_state"`
+}
+
+// TP-Link KL430 light strip. On top of the bulb features, every segment
+// can have its own color and lighting effects can be played.
+type LightStrip struct {
+	Bulb
+}
+
+type lightStripInfoResponse struct {
+	System struct {
+		Info *LightStripInfo `json:"get_sysinfo"`
+	} `json:"system"`
+}
+
+// Get System Info, including segment count and lighting effect state
+func (p *LightStrip) LightStripInfo() (*LightStripInfo, error) {
+	return p.LightStripInfoContext(context.Background())
+}
+
+func (p *LightStrip) LightStripInfoContext(ctx context.Context) (*LightStripInfo, error) {
+	r := lightStripInfoResponse{}
+	if err := p.call(ctx, getInfoRequest(), &r); err != nil {
+		return nil, err
+	}
+
+	if r.System.Info == nil {
+		return nil, ErrUnsupported
+	}
+	return r.System.Info, nil
+}
+
+// Gets the number of segments
+func (p *LightStrip) Segments() (int, error) {
+	return p.SegmentsContext(context.Background())
+}
+
+func (p *LightStrip) SegmentsContext(ctx context.Context) (int, error) {
+	info, err := p.LightStripInfoContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return info.Length, nil
+}
+
+// Sets the color of ranges of segments and turns the strip on
+func (p *LightStrip) SetSegmentColors(colors []SegmentColor) error {
+	return p.SetSegmentColorsContext(context.Background(), colors)
+}
+
+func (p *LightStrip) SetSegmentColorsContext(ctx context.Context, colors []SegmentColor) error {
+	if len(colors) == 0 {
+		return fmt.Errorf("no segment color given")
+	}
+
+	for _, c := range colors {
+		if c.Start < 0 || c.End < c.Start {
+			return fmt.Errorf("invalid segment range %d-%d", c.Start, c.End)
+		}
+		if err := validHSV(c.Hue, c.Saturation, c.Brightness); err != nil {
+			return err
+		}
+	}
+	return p.transition(ctx, &lightStateParams{OnOff: intPtr(1), Groups: colors})
+}
+
+// Uploads and plays a lighting effect
+func (p *LightStrip) SetLightingEffect(effect LightingEffect) error {
+	return p.SetLightingEffectContext(context.Background(), effect)
+}
+
+func (p *LightStrip) SetLightingEffectContext(ctx context.Context, effect LightingEffect) error {
+	if effect.Name == "" {
+		return fmt.Errorf("lighting effect name is required")
+	}
+
+	effect.Enable = ENABLED
+	_, err := p.do(ctx, setLightingEffectRequest(effect))
+	return err
+}
+
+func NewLightStrip(ip string, timeout time.Duration, opts ...Option) *LightStrip {
+	return &LightStrip{Bulb{client: newClient(ip, timeout, opts...), lightStrip: true}}
+}
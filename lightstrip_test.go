@@ -0,0 +1,79 @@
+package tplink
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestLightStrip(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		GET_INFO: `{"system":{"get_sysinfo":{"model":"KL430(US)","mic_type":"IOT.SMARTBULB","length":16,
+			"light_state":{"on_off":1,"mode":"normal","hue":0,"saturation":0,"color_temp":2700,"brightness":50},
+			"lighting_effect_state":{"enable":0,"name":"Aurora","id":"xqUxDhbAhNLqulcuRMyPBmVGyTOyEMEu","custom":0,"brightness":100},
+			"err_code":0}}}`,
+		`{"smartlife.iot.lightStrip":{"set_light_state":{"on_off":1,"groups":[[0,7,0,100,50,0],[8,15,240,100,50,0]],"transition_period":0,"ignore_default":1}}}`: `{"smartlife.iot.lightStrip":{"set_light_state":{"err_code":0}}}`,
+		`{"smartlife.iot.lightStrip":{"set_light_state":{"on_off":0,"transition_period":0,"ignore_default":1}}}`:                                                 `{"smartlife.iot.lightStrip":{"set_light_state":{"err_code":0}}}`,
+	}}
+
+	d, err := Connect(context.Background(), "10.0.0.1", WithTransport(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strip, ok := d.(*LightStrip)
+	if !ok {
+		t.Fatalf("expecting *LightStrip; got %T", d)
+	}
+
+	if n, err := strip.Segments(); err != nil || n != 16 {
+		t.Errorf("expecting 16 segments; got %d, %v", n, err)
+	}
+
+	info, err := strip.LightStripInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.LightingEffectState == nil || info.LightingEffectState.Name != "Aurora" || info.LightState.ColorTemp != 2700 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	err = strip.SetSegmentColors([]SegmentColor{
+		{Start: 0, End: 7, Hue: 0, Saturation: 100, Brightness: 50},
+		{Start: 8, End: 15, Hue: 240, Saturation: 100, Brightness: 50},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	if err := strip.TurnOff(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLightingEffectRequest(t *testing.T) {
+	effect := LightingEffect{
+		ID:                "custom-1",
+		Name:              "Police",
+		Custom:            1,
+		Brightness:        100,
+		Type:              "sequence",
+		Transition:        400,
+		Direction:         1,
+		Spread:            1,
+		Segments:          []int{0},
+		ExpansionStrategy: 1,
+		Sequence:          [][]int{{0, 100, 100}, {240, 100, 100}},
+	}
+
+	req := setLightingEffectRequest(effect)
+	decoded := request{}
+	if err := json.Unmarshal([]byte(marshal(t, req)), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	e := decoded.LightStrip.SetLightingEffect
+	if e == nil || e.Name != "Police" || len(e.Sequence) != 2 || e.Sequence[1][0] != 240 {
+		t.Errorf("unexpected effect: %+v", e)
+	}
+}
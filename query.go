@@ -0,0 +1,63 @@
+package tplink
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Query sends an arbitrary request, made of module -> method -> parameters, e.g.
+//
+//	plug.Query(map[string]map[string]interface{}{
+//		"count_down": {"get_rules": nil},
+//	})
+//
+// and returns the raw reply of every method. When the device reports an error,
+// the first one is returned as a *DeviceError along with the whole reply.
+func (c *client) Query(req map[string]map[string]interface{}) (map[string]map[string]json.RawMessage, error) {
+	return c.QueryContext(context.Background(), req)
+}
+
+func (c *client) QueryContext(ctx context.Context, req map[string]map[string]interface{}) (map[string]map[string]json.RawMessage, error) {
+	if len(c.childIDs) > 0 {
+		r := map[string]map[string]interface{}{}
+		for k, v := range req {
+			r[k] = v
+		}
+		r["context"] = map[string]interface{}{"child_ids": c.childIDs}
+		req = r
+	}
+
+	cmd, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.exec(ctx, string(cmd))
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := deviceErrors(data)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(data), &modules); err != nil {
+		return nil, err
+	}
+
+	r := map[string]map[string]json.RawMessage{}
+	for module, raw := range modules {
+		methods := map[string]json.RawMessage{}
+		if err := json.Unmarshal(raw, &methods); err != nil {
+			continue
+		}
+		r[module] = methods
+	}
+
+	if len(errs) > 0 {
+		return r, errs[0]
+	}
+	return r, nil
+}
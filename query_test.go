@@ -0,0 +1,37 @@
+package tplink
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuery(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		`{"count_down":{"get_rules":null},"system":{"get_dev_icon":{}}}`: `{"count_down":{"get_rules":{"rule_list":[{"id":"A","delay":60}],"err_code":0}},"system":{"get_dev_icon":{"err_code":-2,"err_msg":"method not support"}}}`,
+	}}
+	plug := NewHS100("10.0.0.1", time.Second, WithTransport(f))
+
+	r, err := plug.Query(map[string]map[string]interface{}{
+		"count_down": {"get_rules": nil},
+		"system":     {"get_dev_icon": struct{}{}},
+	})
+	if !errors.Is(err, ErrMethodNotSupported) {
+		t.Errorf("expecting %v; got %v", ErrMethodNotSupported, err)
+	}
+
+	rules := struct {
+		List []struct {
+			ID    string `json:"id"`
+			Delay int    `json:"delay"`
+		} `json:"rule_list"`
+	}{}
+	if err := json.Unmarshal(r["count_down"]["get_rules"], &rules); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rules.List) != 1 || rules.List[0].ID != "A" || rules.List[0].Delay != 60 {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+}
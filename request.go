@@ -0,0 +1,664 @@
+package tplink
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// request is the JSON payload sent to a device, it mirrors Response.
+// Only the modules and methods that are set are sent, every string goes
+// through encoding/json so user input is always escaped.
+type request struct {
+	Context   *requestContext   `json:"context,omitempty"`
+	System    *systemRequest    `json:"system,omitempty"`
+	NetIf     *netIfRequest     `json:"netif,omitempty"`
+	CNCloud   *cloudRequest     `json:"cnCloud,omitempty"`
+	Time      *timeRequest      `json:"time,omitempty"`
+	Schedule  *scheduleRequest  `json:"schedule,omitempty"`
+	EMeter    *emeterRequest    `json:"emeter,omitempty"`
+	Dimmer    *dimmerRequest    `json:"smartlife.iot.dimmer,omitempty"`
+	Countdown *countdownRequest `json:"count_down,omitempty"`
+	AntiTheft *antiTheftRequest `json:"anti_theft,omitempty"`
+
+	// --- Smart bulbs ---
+	CommonSystem *commonSystemRequest `json:"smartlife.iot.common.system,omitempty"`
+	Lighting     *lightingRequest     `json:"smartlife.iot.smartbulb.lightingservice,omitempty"`
+	LightStrip   *lightStripRequest   `json:"smartlife.iot.lightStrip,omitempty"`
+}
+
+// requestContext addresses the outlets of a power strip
+type requestContext struct {
+	ChildIDs []string `json:"child_ids,omitempty"`
+}
+
+// empty is sent as {}
+type empty struct{}
+
+// null is sent as null, some methods expect it instead of {}
+type null struct{}
+
+func (null) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+type systemRequest struct {
+	GetSysInfo    *empty       `json:"get_sysinfo,omitempty"`
+	Reboot        *delayParams `json:"reboot,omitempty"`
+	Reset         *delayParams `json:"reset,omitempty"`
+	SetDevAlias   *aliasParams `json:"set_dev_alias,omitempty"`
+	SetLedOff     *ledParams   `json:"set_led_off,omitempty"`
+	SetRelayState *relayParams `json:"set_relay_state,omitempty"`
+
+	SetDevLocation *locationParams `json:"set_dev_location,omitempty"`
+
+	DownloadFirmware *urlParams `json:"download_firmware,omitempty"`
+	GetDownloadState *empty     `json:"get_download_state,omitempty"`
+	FlashFirmware    *empty     `json:"flash_firmware,omitempty"`
+}
+
+type delayParams struct {
+	Delay int `json:"delay"`
+}
+
+type aliasParams struct {
+	Alias string `json:"alias"`
+}
+
+type ledParams struct {
+	Off int `json:"off"`
+}
+
+type relayParams struct {
+	State int `json:"state"`
+}
+
+type netIfRequest struct {
+	GetScanInfo *scanInfoParams `json:"get_scaninfo,omitempty"`
+	SetStaInfo  *wifiParams     `json:"set_stainfo,omitempty"`
+}
+
+type scanInfoParams struct {
+	Refresh int `json:"refresh"`
+}
+
+type wifiParams struct {
+	SSID     string `json:"ssid"`
+	Password string `json:"password"`
+	KeyType  int    `json:"key_type"`
+}
+
+type cloudRequest struct {
+	GetInfo      *null         `json:"get_info,omitempty"`
+	SetServerURL *serverParams `json:"set_server_url,omitempty"`
+	Bind         *bindParams   `json:"bind,omitempty"`
+	Unbind       *null         `json:"unbind,omitempty"`
+}
+
+type locationParams struct {
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	LatitudeI  int     `json:"latitude_i"`
+	LongitudeI int     `json:"longitude_i"`
+}
+
+type urlParams struct {
+	URL string `json:"url"`
+}
+
+type serverParams struct {
+	Server string `json:"server"`
+}
+
+type bindParams struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type timeRequest struct {
+	GetTime     *empty          `json:"get_time,omitempty"`
+	GetTimeZone *null           `json:"get_timezone,omitempty"`
+	SetTimeZone *timeZoneParams `json:"set_timezone,omitempty"`
+}
+
+type timeZoneParams struct {
+	Year   int `json:"year"`
+	Month  int `json:"month"`
+	Day    int `json:"mday"`
+	Hour   int `json:"hour"`
+	Minute int `json:"min"`
+	Second int `json:"sec"`
+	Index  int `json:"index"`
+}
+
+type scheduleRequest struct {
+	GetNextAction    *null         `json:"get_next_action,omitempty"`
+	GetRules         *null         `json:"get_rules,omitempty"`
+	AddRule          *ruleParams   `json:"add_rule,omitempty"`
+	EditRule         *ruleParams   `json:"edit_rule,omitempty"`
+	DeleteRule       *idParams     `json:"delete_rule,omitempty"`
+	DeleteAllRules   *null         `json:"delete_all_rules,omitempty"`
+	EraseRuntimeStat *null         `json:"erase_runtime_stat,omitempty"`
+	SetOverallEnable *enableParams `json:"set_overall_enable,omitempty"`
+}
+
+type ruleParams struct {
+	StartTimeOpt TimeOption `json:"stime_opt"`
+	WeekDays     []int      `json:"wday"`
+	StartMinutes int        `json:"smin"`
+	Enable       int        `json:"enable"`
+	Repeat       Action     `json:"repeat"`
+	EndTimeOpt   int        `json:"etime_opt"`
+	ID           string     `json:"id,omitempty"`
+	Name         string     `json:"name"`
+	EndAction    int        `json:"eact"`
+	Month        int        `json:"month"`
+	StartAction  Action     `json:"sact"`
+	Year         int        `json:"year"`
+	Longitude    float64    `json:"longitude"`
+	Day          int        `json:"day"`
+	Force        int        `json:"force"`
+	Latitude     float64    `json:"latitude"`
+	EndMinutes   int        `json:"emin"`
+	StartOffset  int        `json:"soffset,omitempty"`
+	EndOffset    int        `json:"eoffset,omitempty"`
+}
+
+type countdownRequest struct {
+	GetRules       *null            `json:"get_rules,omitempty"`
+	AddRule        *countdownParams `json:"add_rule,omitempty"`
+	EditRule       *countdownParams `json:"edit_rule,omitempty"`
+	DeleteRule     *idParams        `json:"delete_rule,omitempty"`
+	DeleteAllRules *null            `json:"delete_all_rules,omitempty"`
+}
+
+type countdownParams struct {
+	ID     string `json:"id,omitempty"`
+	Enable int    `json:"enable"`
+	Delay  int    `json:"delay"` // seconds
+	Action Action `json:"act"`
+	Name   string `json:"name"`
+}
+
+type antiTheftRequest struct {
+	GetRules         *null           `json:"get_rules,omitempty"`
+	AddRule          *awayRuleParams `json:"add_rule,omitempty"`
+	EditRule         *awayRuleParams `json:"edit_rule,omitempty"`
+	DeleteRule       *idParams       `json:"delete_rule,omitempty"`
+	DeleteAllRules   *null           `json:"delete_all_rules,omitempty"`
+	SetOverallEnable *enableParams   `json:"set_overall_enable,omitempty"`
+}
+
+type awayRuleParams struct {
+	StartTimeOpt TimeOption `json:"stime_opt"`
+	WeekDays     []int      `json:"wday"`
+	StartMinutes int        `json:"smin"`
+	Enable       int        `json:"enable"`
+	Frequency    int        `json:"frequency"`
+	Repeat       Action     `json:"repeat"`
+	EndTimeOpt   TimeOption `json:"etime_opt"`
+	Duration     int        `json:"duration"`
+	ID           string     `json:"id,omitempty"`
+	Name         string     `json:"name"`
+	LastFor      int        `json:"lastfor"`
+	Month        int        `json:"month"`
+	Year         int        `json:"year"`
+	Longitude    float64    `json:"longitude"`
+	Day          int        `json:"day"`
+	Latitude     float64    `json:"latitude"`
+	Force        int        `json:"force"`
+	EndMinutes   int        `json:"emin"`
+}
+
+type idParams struct {
+	ID string `json:"id"`
+}
+
+type enableParams struct {
+	Enable int `json:"enable"`
+}
+
+type emeterRequest struct {
+	GetRealtime      *empty             `json:"get_realtime,omitempty"`
+	GetVGainIGain    *empty             `json:"get_vgain_igain,omitempty"`
+	SetVGainIGain    *Gains             `json:"set_vgain_igain,omitempty"`
+	StartCalibration *calibrationParams `json:"start_calibration,omitempty"`
+	GetDayStat       *dayStatParams     `json:"get_daystat,omitempty"`
+	GetMonthStat     *monthStatParams   `json:"get_monthstat,omitempty"`
+	EraseEmeterStat  *null              `json:"erase_emeter_stat,omitempty"`
+}
+
+type calibrationParams struct {
+	VTarget int `json:"vtarget"`
+	ITarget int `json:"itarget"`
+}
+
+type dayStatParams struct {
+	Month int `json:"month"`
+	Year  int `json:"year"`
+}
+
+type monthStatParams struct {
+	Year int `json:"year"`
+}
+
+type dimmerRequest struct {
+	GetDimmerParameters  *empty            `json:"get_dimmer_parameters,omitempty"`
+	SetBrightness        *brightnessParams `json:"set_brightness,omitempty"`
+	SetDimmerTransition  *transitionParams `json:"set_dimmer_transition,omitempty"`
+	SetDoubleClickAction *gestureParams    `json:"set_double_click_action,omitempty"`
+	SetLongPressAction   *gestureParams    `json:"set_long_press_action,omitempty"`
+	SetFadeOnTime        *fadeTimeParams   `json:"set_fade_on_time,omitempty"`
+	SetFadeOffTime       *fadeTimeParams   `json:"set_fade_off_time,omitempty"`
+}
+
+type brightnessParams struct {
+	Brightness int `json:"brightness"`
+}
+
+type transitionParams struct {
+	Brightness int `json:"brightness"`
+	Duration   int `json:"duration"` // milliseconds
+}
+
+type gestureParams struct {
+	Mode GestureAction `json:"mode"`
+}
+
+type fadeTimeParams struct {
+	FadeTime int `json:"fadeTime"` // milliseconds
+}
+
+type commonSystemRequest struct {
+	Reboot      *delayParams `json:"reboot,omitempty"`
+	SetDevAlias *aliasParams `json:"set_dev_alias,omitempty"`
+}
+
+type lightingRequest struct {
+	GetLightState        *empty            `json:"get_light_state,omitempty"`
+	TransitionLightState *lightStateParams `json:"transition_light_state,omitempty"`
+	SetPreferredState    *PreferredState   `json:"set_preferred_state,omitempty"`
+}
+
+// lightStateParams only holds the values to change
+type lightStateParams struct {
+	OnOff            *int   `json:"on_off,omitempty"`
+	Mode             string `json:"mode,omitempty"`
+	Hue              *int   `json:"hue,omitempty"`
+	Saturation       *int   `json:"saturation,omitempty"`
+	ColorTemp        *int   `json:"color_temp,omitempty"`
+	Brightness       *int   `json:"brightness,omitempty"`
+	TransitionPeriod int    `json:"transition_period"` // milliseconds
+	IgnoreDefault    int    `json:"ignore_default"`
+
+	Groups []SegmentColor `json:"groups,omitempty"` // light strips only
+}
+
+type lightStripRequest struct {
+	GetLightState     *empty            `json:"get_light_state,omitempty"`
+	SetLightState     *lightStateParams `json:"set_light_state,omitempty"`
+	SetLightingEffect *LightingEffect   `json:"set_lighting_effect,omitempty"`
+}
+
+// --- Command builders, one per command of the const block ---
+
+func getInfoRequest() *request {
+	return &request{System: &systemRequest{GetSysInfo: &empty{}}}
+}
+
+func rebootRequest() *request {
+	return &request{System: &systemRequest{Reboot: &delayParams{Delay: 1}}}
+}
+
+// setDevLocationRequest sends both the degrees and the 1/10000 degree formats, each firmware reading its own
+func setDevLocationRequest(latitude float64, longitude float64) *request {
+	return &request{System: &systemRequest{SetDevLocation: &locationParams{
+		Latitude:   latitude,
+		Longitude:  longitude,
+		LatitudeI:  int(math.Round(latitude * 10000)),
+		LongitudeI: int(math.Round(longitude * 10000)),
+	}}}
+}
+
+func downloadFirmwareRequest(url string) *request {
+	return &request{System: &systemRequest{DownloadFirmware: &urlParams{URL: url}}}
+}
+
+func getDownloadStateRequest() *request {
+	return &request{System: &systemRequest{GetDownloadState: &empty{}}}
+}
+
+func flashFirmwareRequest() *request {
+	return &request{System: &systemRequest{FlashFirmware: &empty{}}}
+}
+
+func resetRequest() *request {
+	return &request{System: &systemRequest{Reset: &delayParams{Delay: 1}}}
+}
+
+func setAliasRequest(alias string) *request {
+	return &request{System: &systemRequest{SetDevAlias: &aliasParams{Alias: alias}}}
+}
+
+func setLedRequest(action Action) *request {
+	// the device expects the led "off" flag, the opposite of the action
+	off := 1
+	if action == ON {
+		off = 0
+	}
+	return &request{System: &systemRequest{SetLedOff: &ledParams{Off: off}}}
+}
+
+func setRelayStateRequest(action Action) *request {
+	return &request{System: &systemRequest{SetRelayState: &relayParams{State: int(action)}}}
+}
+
+func scanWifiRequest() *request {
+	return &request{NetIf: &netIfRequest{GetScanInfo: &scanInfoParams{Refresh: 1}}}
+}
+
+func setWifiRequest(ssid string, password string, keyType int) *request {
+	return &request{NetIf: &netIfRequest{SetStaInfo: &wifiParams{SSID: ssid, Password: password, KeyType: keyType}}}
+}
+
+func getCloudInfoRequest() *request {
+	return &request{CNCloud: &cloudRequest{GetInfo: &null{}}}
+}
+
+func setCloudURLRequest(url string) *request {
+	return &request{CNCloud: &cloudRequest{SetServerURL: &serverParams{Server: url}}}
+}
+
+func cloudBindRequest(username string, password string) *request {
+	return &request{CNCloud: &cloudRequest{Bind: &bindParams{Username: username, Password: password}}}
+}
+
+func cloudUnbindRequest() *request {
+	return &request{CNCloud: &cloudRequest{Unbind: &null{}}}
+}
+
+func getTimeRequest() *request {
+	return &request{Time: &timeRequest{GetTime: &empty{}}}
+}
+
+func getTimeZoneRequest() *request {
+	return &request{Time: &timeRequest{GetTimeZone: &null{}}}
+}
+
+func getTimeAndZoneRequest() *request {
+	return &request{Time: &timeRequest{GetTime: &empty{}, GetTimeZone: &null{}}}
+}
+
+func setTimeZoneRequest(t time.Time, index int) *request {
+	return &request{Time: &timeRequest{SetTimeZone: &timeZoneParams{
+		Year:   t.Year(),
+		Month:  int(t.Month()),
+		Day:    t.Day(),
+		Hour:   t.Hour(),
+		Minute: t.Minute(),
+		Second: t.Second(),
+		Index:  index,
+	}}}
+}
+
+func getNextActionRequest() *request {
+	return &request{Schedule: &scheduleRequest{GetNextAction: &null{}}}
+}
+
+func getRulesRequest() *request {
+	return &request{Schedule: &scheduleRequest{GetRules: &null{}}}
+}
+
+func newRuleParams(id string, timeOpt TimeOption, name string, days Days, action Action, minutes int, enable int, year int, month int, day int) *ruleParams {
+	weekdays := days.weekdays()
+	repeat := OFF
+	for _, d := range weekdays {
+		if d == 1 {
+			repeat = ON
+		}
+	}
+
+	return &ruleParams{
+		StartTimeOpt: timeOpt,
+		WeekDays:     weekdays,
+		StartMinutes: minutes,
+		Enable:       enable,
+		Repeat:       repeat,
+		EndTimeOpt:   -1,
+		ID:           id,
+		Name:         name,
+		EndAction:    -1,
+		Month:        month,
+		StartAction:  action,
+		Year:         year,
+		Day:          day,
+	}
+}
+
+// withEnd adds a second action to the rule, turning it into a time range
+func (p *ruleParams) withEnd(timeOpt TimeOption, action Action, minutes int, offset int) *ruleParams {
+	p.EndTimeOpt = int(timeOpt)
+	p.EndAction = int(action)
+	p.EndMinutes = minutes
+	p.EndOffset = offset
+	return p
+}
+
+func addRuleRequest(rule *ruleParams) *request {
+	return &request{Schedule: &scheduleRequest{AddRule: rule, SetOverallEnable: &enableParams{Enable: ENABLED}}}
+}
+
+func editRuleRequest(rule *ruleParams) *request {
+	return &request{Schedule: &scheduleRequest{EditRule: rule}}
+}
+
+func deleteRuleRequest(id string) *request {
+	return &request{Schedule: &scheduleRequest{DeleteRule: &idParams{ID: id}}}
+}
+
+func deleteAllRulesRequest() *request {
+	return &request{Schedule: &scheduleRequest{DeleteAllRules: &null{}, EraseRuntimeStat: &null{}}}
+}
+
+func getCountdownRulesRequest() *request {
+	return &request{Countdown: &countdownRequest{GetRules: &null{}}}
+}
+
+func addCountdownRuleRequest(rule *countdownParams) *request {
+	return &request{Countdown: &countdownRequest{AddRule: rule}}
+}
+
+func editCountdownRuleRequest(rule *countdownParams) *request {
+	return &request{Countdown: &countdownRequest{EditRule: rule}}
+}
+
+func deleteCountdownRuleRequest(id string) *request {
+	return &request{Countdown: &countdownRequest{DeleteRule: &idParams{ID: id}}}
+}
+
+func deleteAllCountdownRulesRequest() *request {
+	return &request{Countdown: &countdownRequest{DeleteAllRules: &null{}}}
+}
+
+func newAwayRuleParams(id string, name string, days Days, startMinutes int, endMinutes int, frequency int, duration int, enable int) *awayRuleParams {
+	weekdays := days.weekdays()
+	repeat := OFF
+	for _, d := range weekdays {
+		if d == 1 {
+			repeat = ON
+		}
+	}
+
+	return &awayRuleParams{
+		StartTimeOpt: NONE,
+		WeekDays:     weekdays,
+		StartMinutes: startMinutes,
+		Enable:       enable,
+		Frequency:    frequency,
+		Repeat:       repeat,
+		EndTimeOpt:   NONE,
+		Duration:     duration,
+		ID:           id,
+		Name:         name,
+		LastFor:      1,
+		EndMinutes:   endMinutes,
+	}
+}
+
+func getAwayRulesRequest() *request {
+	return &request{AntiTheft: &antiTheftRequest{GetRules: &null{}}}
+}
+
+func addAwayRuleRequest(rule *awayRuleParams) *request {
+	return &request{AntiTheft: &antiTheftRequest{AddRule: rule, SetOverallEnable: &enableParams{Enable: ENABLED}}}
+}
+
+func editAwayRuleRequest(rule *awayRuleParams) *request {
+	return &request{AntiTheft: &antiTheftRequest{EditRule: rule}}
+}
+
+func deleteAwayRuleRequest(id string) *request {
+	return &request{AntiTheft: &antiTheftRequest{DeleteRule: &idParams{ID: id}}}
+}
+
+func deleteAllAwayRulesRequest() *request {
+	return &request{AntiTheft: &antiTheftRequest{DeleteAllRules: &null{}}}
+}
+
+func setAwayModeEnableRequest(enable int) *request {
+	return &request{AntiTheft: &antiTheftRequest{SetOverallEnable: &enableParams{Enable: enable}}}
+}
+
+func getMeterRequest() *request {
+	return &request{
+		EMeter: &emeterRequest{GetRealtime: &empty{}},
+	}
+}
+
+func getDailyStatsRequest(month int, year int) *request {
+	return &request{EMeter: &emeterRequest{GetDayStat: &dayStatParams{Month: month, Year: year}}}
+}
+
+func getMonthlyStatsRequest(year int) *request {
+	return &request{EMeter: &emeterRequest{GetMonthStat: &monthStatParams{Year: year}}}
+}
+
+func getVGainIGainRequest() *request {
+	return &request{EMeter: &emeterRequest{GetVGainIGain: &empty{}}}
+}
+
+func setVGainIGainRequest(vgain int, igain int) *request {
+	return &request{EMeter: &emeterRequest{SetVGainIGain: &Gains{VGain: vgain, IGain: igain}}}
+}
+
+func startCalibrationRequest(vtarget int, itarget int) *request {
+	return &request{EMeter: &emeterRequest{StartCalibration: &calibrationParams{VTarget: vtarget, ITarget: itarget}}}
+}
+
+func eraseAllStatsRequest() *request {
+	return &request{EMeter: &emeterRequest{EraseEmeterStat: &null{}}}
+}
+
+func getDimmerParametersRequest() *request {
+	return &request{Dimmer: &dimmerRequest{GetDimmerParameters: &empty{}}}
+}
+
+func setBrightnessRequest(level int) *request {
+	return &request{Dimmer: &dimmerRequest{SetBrightness: &brightnessParams{Brightness: level}}}
+}
+
+func setDimmerTransitionRequest(level int, duration time.Duration) *request {
+	return &request{Dimmer: &dimmerRequest{SetDimmerTransition: &transitionParams{Brightness: level, Duration: int(duration / time.Millisecond)}}}
+}
+
+func setDoubleClickActionRequest(action GestureAction) *request {
+	return &request{Dimmer: &dimmerRequest{SetDoubleClickAction: &gestureParams{Mode: action}}}
+}
+
+func setLongPressActionRequest(action GestureAction) *request {
+	return &request{Dimmer: &dimmerRequest{SetLongPressAction: &gestureParams{Mode: action}}}
+}
+
+func setFadeOnTimeRequest(d time.Duration) *request {
+	return &request{Dimmer: &dimmerRequest{SetFadeOnTime: &fadeTimeParams{FadeTime: int(d / time.Millisecond)}}}
+}
+
+func setFadeOffTimeRequest(d time.Duration) *request {
+	return &request{Dimmer: &dimmerRequest{SetFadeOffTime: &fadeTimeParams{FadeTime: int(d / time.Millisecond)}}}
+}
+
+func bulbRebootRequest() *request {
+	return &request{CommonSystem: &commonSystemRequest{Reboot: &delayParams{Delay: 1}}}
+}
+
+func bulbSetAliasRequest(alias string) *request {
+	return &request{CommonSystem: &commonSystemRequest{SetDevAlias: &aliasParams{Alias: alias}}}
+}
+
+func getLightStateRequest() *request {
+	return &request{Lighting: &lightingRequest{GetLightState: &empty{}}}
+}
+
+func transitionLightStateRequest(state *lightStateParams) *request {
+	return &request{Lighting: &lightingRequest{TransitionLightState: state}}
+}
+
+func setPreferredStateRequest(preset PreferredState) *request {
+	return &request{Lighting: &lightingRequest{SetPreferredState: &preset}}
+}
+
+func getStripLightStateRequest() *request {
+	return &request{LightStrip: &lightStripRequest{GetLightState: &empty{}}}
+}
+
+func setStripLightStateRequest(state *lightStateParams) *request {
+	return &request{LightStrip: &lightStripRequest{SetLightState: state}}
+}
+
+func setLightingEffectRequest(effect LightingEffect) *request {
+	return &request{LightStrip: &lightStripRequest{SetLightingEffect: &effect}}
+}
+
+// send encodes req and returns the raw reply of the device
+func (c *client) send(ctx context.Context, req *request) (string, error) {
+	if len(c.childIDs) > 0 {
+		r := *req
+		r.Context = &requestContext{ChildIDs: c.childIDs}
+		req = &r
+	}
+
+	cmd, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	return c.exec(ctx, string(cmd))
+}
+
+// do sends req and decodes the reply of the device.
+// The first error reported by the device is returned as a *DeviceError.
+func (c *client) do(ctx context.Context, req *request) (*Response, error) {
+	r := Response{}
+	if err := c.call(ctx, req, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// call is like do, decoding the reply into v
+func (c *client) call(ctx context.Context, req *request, v interface{}) error {
+	data, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	errs, err := deviceErrors(data)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return json.Unmarshal([]byte(data), v)
+}
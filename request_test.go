@@ -0,0 +1,140 @@
+package tplink
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// sameJSON reports whether a and b decode to the same value
+func sameJSON(t *testing.T, a string, b string) bool {
+	var va, vb interface{}
+	if err := json.Unmarshal([]byte(a), &va); err != nil {
+		t.Fatalf("invalid JSON %s: %s", a, err)
+	}
+	if err := json.Unmarshal([]byte(b), &vb); err != nil {
+		t.Fatalf("invalid JSON %s: %s", b, err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func marshal(t *testing.T, req *request) string {
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRequestMatchesCommand(t *testing.T) {
+	ts := time.Date(2018, time.March, 4, 5, 6, 7, 0, time.UTC)
+	days := Days{Monday: true, Friday: true}
+
+	tt := []struct {
+		req       *request
+		expecting string
+	}{
+		{getInfoRequest(), GET_INFO},
+		{rebootRequest(), REBOOT},
+		{resetRequest(), RESET},
+		{setAliasRequest("Plug1"), fmt.Sprintf(SET_ALIAS, "Plug1")},
+		{setLedRequest(ON), TURN_LED_ON},
+		{setLedRequest(OFF), TURN_LED_OFF},
+		{setRelayStateRequest(ON), TURN_ON},
+		{setRelayStateRequest(OFF), TURN_OFF},
+		{scanWifiRequest(), SCAN_WIFI},
+		{setWifiRequest("home", "secret", 3), fmt.Sprintf(SET_WIFI, "home", "secret", 3)},
+		{getCloudInfoRequest(), GET_CLOUD_INFO},
+		{setCloudURLRequest("devs.tplinkcloud.com"), fmt.Sprintf(SET_CLOUD_URL, "devs.tplinkcloud.com")},
+		{cloudBindRequest("me@example.com", "secret"), fmt.Sprintf(CLOUD_BIND, "me@example.com", "secret")},
+		{cloudUnbindRequest(), CLOUD_UNBIND},
+		{getTimeRequest(), GET_TIME},
+		{getTimeZoneRequest(), GET_TIMEZONE},
+		{setTimeZoneRequest(ts, 18), fmt.Sprintf(SET_TIMEZONE, 2018, 3, 4, 5, 6, 7, 18)},
+		{getNextActionRequest(), GET_NEXT_SCHEDULE_ACTION},
+		{getRulesRequest(), GET_SCHEDULE_RULES_LIST},
+		{
+			addRuleRequest(newRuleParams("", SUNSET, "lamp", days, ON, 0, ENABLED, 0, 0, 0)),
+			fmt.Sprintf(ADD_SCHEDULE_RULE, SUNSET, days, 0, ENABLED, ON, "lamp", 0, ON, 0, 0),
+		},
+		{
+			addRuleRequest(newRuleParams("", NONE, "lamp", days, ON, 1080, ENABLED, 0, 0, 0).withEnd(NONE, OFF, 1380, 0)),
+			`{"schedule":{"add_rule":{"stime_opt":0,"wday":[0,1,0,0,0,1,0],"smin":1080,"enable":1,"repeat":1,"etime_opt":0,"name":"lamp","eact":0,"month":0,"sact":1,"year":0,"longitude":0,"day":0,"force":0,"latitude":0,"emin":1380},"set_overall_enable":{"enable":1}}}`,
+		},
+		{
+			editRuleRequest(newRuleParams("ABC", NONE, "lamp", Days{}, OFF, 600, DISABLED, 2018, 3, 4)),
+			fmt.Sprintf(EDIT_SCHEDULE_RULE, NONE, Days{}, 600, DISABLED, OFF, "ABC", "lamp", 3, OFF, 2018, 4),
+		},
+		{deleteRuleRequest("ABC"), fmt.Sprintf(DELETE_SCHEDULE_RULE, "ABC")},
+		{deleteAllRulesRequest(), DELETE_ALL_SCHEDULE_RULE},
+		{getMeterRequest(), GET_METER},
+		{getDailyStatsRequest(3, 2018), fmt.Sprintf(GET_DAILY_STATS, 3, 2018)},
+		{getMonthlyStatsRequest(2018), fmt.Sprintf(GET_MONTHLY_STATS, 2018)},
+		{eraseAllStatsRequest(), ERASE_ALL_STATS},
+	}
+
+	for _, v := range tt {
+		if s := marshal(t, v.req); !sameJSON(t, s, v.expecting) {
+			t.Errorf("expecting %s; got %s", v.expecting, s)
+		}
+	}
+}
+
+func TestRequestEscaping(t *testing.T) {
+	hostile := []string{
+		`"`,
+		`\`,
+		`\"`,
+		`Plug1","alias":"injected`,
+		`"}},"system":{"reboot":{"delay":1}}}`,
+		"line\nbreak\ttab\x00nul",
+		`</script><script>`,
+		`{"a":1}`,
+		"ünïcødé ☃",
+	}
+
+	for _, s := range hostile {
+		tt := []struct {
+			req       *request
+			expecting *request
+		}{
+			{setAliasRequest(s), &request{System: &systemRequest{SetDevAlias: &aliasParams{Alias: s}}}},
+			{setWifiRequest(s, s, 3), &request{NetIf: &netIfRequest{SetStaInfo: &wifiParams{SSID: s, Password: s, KeyType: 3}}}},
+			{setCloudURLRequest(s), &request{CNCloud: &cloudRequest{SetServerURL: &serverParams{Server: s}}}},
+			{cloudBindRequest(s, s), &request{CNCloud: &cloudRequest{Bind: &bindParams{Username: s, Password: s}}}},
+			{deleteRuleRequest(s), &request{Schedule: &scheduleRequest{DeleteRule: &idParams{ID: s}}}},
+		}
+
+		for _, v := range tt {
+			data := marshal(t, v.req)
+			if !json.Valid([]byte(data)) {
+				t.Errorf("invalid JSON for %q: %s", s, data)
+				continue
+			}
+
+			// the payload must decode to the intended request, without any injected module or field
+			if expecting := marshal(t, v.expecting); !sameJSON(t, data, expecting) {
+				t.Errorf("expecting %s; got %s", expecting, data)
+			}
+		}
+
+		rule := newRuleParams(s, NONE, s, Days{}, ON, 0, ENABLED, 0, 0, 0)
+		for _, req := range []*request{addRuleRequest(rule), editRuleRequest(rule)} {
+			decoded := request{}
+			if err := json.Unmarshal([]byte(marshal(t, req)), &decoded); err != nil {
+				t.Errorf("invalid JSON for %q: %s", s, err)
+				continue
+			}
+
+			r := decoded.Schedule.AddRule
+			if r == nil {
+				r = decoded.Schedule.EditRule
+			}
+			if r.Name != s || r.ID != s {
+				t.Errorf("expecting name and id %q; got %q and %q", s, r.Name, r.ID)
+			}
+		}
+	}
+}
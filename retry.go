@@ -0,0 +1,99 @@
+package tplink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// RetryPolicy controls how commands failing at the transport level are retried.
+// Only idempotent commands are retried: repeating e.g. an add_rule after a lost reply
+// would create the rule twice.
+type RetryPolicy struct {
+	Attempts   int              // Total number of attempts, including the first one
+	Backoff    time.Duration    // Delay before the first retry, doubled after every attempt
+	MaxBackoff time.Duration    // Upper bound for the delay, 0 = unbounded
+	Jitter     float64          // Fraction of the delay that is randomized, between 0 and 1
+	Retryable  func(error) bool // Tells whether an error is worth a retry. Defaults to IsRetryable
+}
+
+// A reasonable policy for plugs on a lossy Wi-Fi network
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   3,
+	Backoff:    100 * time.Millisecond,
+	MaxBackoff: 2 * time.Second,
+	Jitter:     0.2,
+}
+
+// Retry failed idempotent commands according to policy
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *client) {
+		c.retry = policy
+	}
+}
+
+// methods that must not be sent twice
+var nonIdempotentMethods = map[string]bool{
+	"add_rule":          true,
+	"reboot":            true,
+	"reset":             true,
+	"download_firmware": true,
+	"flash_firmware":    true,
+	"start_calibration": true,
+}
+
+// isIdempotent reports whether cmd can safely be sent again when its reply was lost
+func isIdempotent(cmd string) bool {
+	req := map[string]map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(cmd), &req); err != nil {
+		return false
+	}
+
+	for _, methods := range req {
+		for method := range methods {
+			if nonIdempotentMethods[method] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsRetryable reports whether err is a transient network failure, such as a dropped datagram
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// delay returns the backoff before the given retry, starting at 1
+func (r RetryPolicy) delay(retry int) time.Duration {
+	d := r.Backoff
+	for i := 1; i < retry && (r.MaxBackoff == 0 || d < r.MaxBackoff); i++ {
+		d *= 2
+	}
+
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+
+	if r.Jitter > 0 {
+		j := float64(d) * r.Jitter
+		d = time.Duration(float64(d) - j + rand.Float64()*2*j)
+	}
+	return d
+}
+
+func (r RetryPolicy) retryable(err error) bool {
+	if r.Retryable != nil {
+		return r.Retryable(err)
+	}
+	return IsRetryable(err)
+}
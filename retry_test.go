@@ -0,0 +1,61 @@
+package tplink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+	addRule := `{"schedule":{"add_rule":{"name":"x"}}}`
+
+	tt := []struct {
+		cmd       string
+		errs      []error
+		expecting int // number of attempts
+		fails     bool
+	}{
+		{GET_INFO, []error{context.DeadlineExceeded}, 2, false},
+		{TURN_ON, []error{context.DeadlineExceeded, context.DeadlineExceeded}, 3, false},
+		{TURN_ON, []error{context.DeadlineExceeded, context.DeadlineExceeded, context.DeadlineExceeded}, 3, true},
+		{GET_INFO, []error{errors.New("not transient")}, 1, true},
+		{addRule, []error{context.DeadlineExceeded}, 1, true},
+		{REBOOT, []error{context.DeadlineExceeded}, 1, true},
+	}
+
+	for _, v := range tt {
+		f := &fakeTransport{errs: v.errs, replies: map[string]string{v.cmd: "{}"}}
+		c := newClient("10.0.0.1", time.Second, WithTransport(f), WithRetry(policy))
+
+		_, err := c.exec(context.Background(), v.cmd)
+		if (err != nil) != v.fails {
+			t.Errorf("%s: unexpected error %v", v.cmd, err)
+		}
+
+		if len(f.sent) != v.expecting {
+			t.Errorf("%s: expecting %d attempts; got %d", v.cmd, v.expecting, len(f.sent))
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tt := []struct {
+		retry     int
+		expecting time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{5, time.Second},
+		{100, time.Second},
+	}
+
+	for _, v := range tt {
+		if d := policy.delay(v.retry); d != v.expecting {
+			t.Errorf("retry %d: expecting %s; got %s", v.retry, v.expecting, d)
+		}
+	}
+}
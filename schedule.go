@@ -0,0 +1,192 @@
+package tplink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// When and what a schedule rule does
+type RuleEvent struct {
+	Action  Action
+	TimeOpt TimeOption
+	Minutes int // minute of the day, 0 to 1439, when TimeOpt is NONE
+	Offset  int // minutes from sunrise or sunset, negative being before, when TimeOpt is SUNRISE or SUNSET
+}
+
+// A schedule rule, e.g. turn on at 18:00 every weekday.
+// The rule repeats on Days, or runs once on Date when no day is set.
+type ScheduleRule struct {
+	ID      string // empty for a new rule
+	Name    string
+	Enabled bool
+	Start   RuleEvent
+	End     *RuleEvent // optional second action, e.g. turn off again at 23:00
+	Days    Days
+	Date    time.Time // only year, month and day are used
+}
+
+func (e RuleEvent) validate() error {
+	if e.Action != ON && e.Action != OFF {
+		return fmt.Errorf("invalid action %d", e.Action)
+	}
+
+	switch e.TimeOpt {
+	case NONE:
+		if e.Minutes < 0 || e.Minutes >= minutesPerDay {
+			return fmt.Errorf("invalid minute %d, must be between 0 and %d", e.Minutes, minutesPerDay-1)
+		}
+		if e.Offset != 0 {
+			return errors.New("offset is only valid for sunrise and sunset rules")
+		}
+	case SUNRISE, SUNSET:
+		if e.Offset <= -minutesPerDay || e.Offset >= minutesPerDay {
+			return fmt.Errorf("invalid offset %d, must be between %d and %d", e.Offset, -minutesPerDay+1, minutesPerDay-1)
+		}
+		if e.Minutes != 0 {
+			return errors.New("minutes are only valid for rules at a fixed time, use offset")
+		}
+	default:
+		return fmt.Errorf("invalid time option %d", e.TimeOpt)
+	}
+	return nil
+}
+
+func (r ScheduleRule) validate() error {
+	if err := r.Start.validate(); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+
+	if r.End != nil {
+		if err := r.End.validate(); err != nil {
+			return fmt.Errorf("end: %w", err)
+		}
+		if r.End.TimeOpt == NONE && r.Start.TimeOpt == NONE && r.End.Minutes == r.Start.Minutes {
+			return errors.New("end must differ from start")
+		}
+	}
+
+	if r.Days == (Days{}) && r.Date.IsZero() {
+		return errors.New("either repeat days or a date must be set")
+	}
+	return nil
+}
+
+func (r ScheduleRule) params() (*ruleParams, error) {
+	if err := r.validate(); err != nil {
+		return nil, fmt.Errorf("invalid schedule rule %q: %w", r.Name, err)
+	}
+
+	enable := DISABLED
+	if r.Enabled {
+		enable = ENABLED
+	}
+
+	var year, month, day int
+	if r.Days == (Days{}) {
+		year, month, day = r.Date.Year(), int(r.Date.Month()), r.Date.Day()
+	}
+
+	params := newRuleParams(r.ID, r.Start.TimeOpt, r.Name, r.Days, r.Start.Action, r.Start.Minutes, enable, year, month, day)
+	params.StartOffset = r.Start.Offset
+	if r.End != nil {
+		params.withEnd(r.End.TimeOpt, r.End.Action, r.End.Minutes, r.End.Offset)
+	}
+	return params, nil
+}
+
+// Converts a rule as returned by the device
+func (r Rule) ScheduleRule() ScheduleRule {
+	rule := ScheduleRule{
+		ID:      r.Id,
+		Name:    r.Name,
+		Enabled: r.Enable == ENABLED,
+		Start:   RuleEvent{Action: r.Action, TimeOpt: r.TimeOpt, Offset: r.Offset},
+	}
+
+	if r.TimeOpt == NONE {
+		rule.Start.Minutes = r.Minutes
+	}
+
+	if r.EndTimeOpt >= 0 && r.EndAction >= 0 {
+		rule.End = &RuleEvent{Action: r.EndAction, TimeOpt: r.EndTimeOpt, Offset: r.EndOffset}
+		if r.EndTimeOpt == NONE {
+			rule.End.Minutes = r.EndMinutes
+		}
+	}
+
+	for i, v := range r.WeekDays {
+		if v != ON {
+			continue
+		}
+		switch time.Weekday(i) {
+		case time.Sunday:
+			rule.Days.Sunday = true
+		case time.Monday:
+			rule.Days.Monday = true
+		case time.Tuesday:
+			rule.Days.Tuesday = true
+		case time.Wednesday:
+			rule.Days.Wednesday = true
+		case time.Thursday:
+			rule.Days.Thursday = true
+		case time.Friday:
+			rule.Days.Friday = true
+		case time.Saturday:
+			rule.Days.Saturday = true
+		}
+	}
+
+	if r.Repeat == 0 && r.Year != 0 {
+		rule.Date = time.Date(r.Year, time.Month(r.Month), r.Day, 0, 0, 0, 0, time.UTC)
+	}
+	return rule
+}
+
+// Add Schedule Rule, validated before being sent. Returns the ID of the new rule.
+func (p *HS100) AddRule(rule ScheduleRule) (string, error) {
+	return p.AddRuleContext(context.Background(), rule)
+}
+
+func (p *HS100) AddRuleContext(ctx context.Context, rule ScheduleRule) (string, error) {
+	rule.ID = ""
+	params, err := rule.params()
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.sunParams(ctx, params); err != nil {
+		return "", err
+	}
+
+	r, err := p.do(ctx, addRuleRequest(params))
+	if err != nil {
+		return "", err
+	}
+
+	return r.Schedule.AddRule.ID, nil
+}
+
+// Update Schedule Rule with rule.ID, validated before being sent
+func (p *HS100) UpdateRule(rule ScheduleRule) error {
+	return p.UpdateRuleContext(context.Background(), rule)
+}
+
+func (p *HS100) UpdateRuleContext(ctx context.Context, rule ScheduleRule) error {
+	if rule.ID == "" {
+		return errors.New("missing schedule rule ID")
+	}
+
+	params, err := rule.params()
+	if err != nil {
+		return err
+	}
+
+	if err := p.sunParams(ctx, params); err != nil {
+		return err
+	}
+
+	_, err = p.do(ctx, editRuleRequest(params))
+	return err
+}
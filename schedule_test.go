@@ -0,0 +1,113 @@
+package tplink
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScheduleRuleValidate(t *testing.T) {
+	weekdays := Days{Monday: true, Tuesday: true, Wednesday: true, Thursday: true, Friday: true}
+
+	tt := []struct {
+		rule  ScheduleRule
+		valid bool
+	}{
+		{ScheduleRule{Start: RuleEvent{Action: ON, Minutes: 18 * 60}, Days: weekdays}, true},
+		{ScheduleRule{Start: RuleEvent{Action: ON, TimeOpt: SUNSET, Offset: -30}, Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}, true},
+		{ScheduleRule{Start: RuleEvent{Action: ON, Minutes: 18 * 60}, End: &RuleEvent{Action: OFF, Minutes: 23 * 60}, Days: weekdays}, true},
+		{ScheduleRule{Start: RuleEvent{Action: ON, Minutes: 1440}, Days: weekdays}, false},
+		{ScheduleRule{Start: RuleEvent{Action: ON, Minutes: 60, Offset: 10}, Days: weekdays}, false},
+		{ScheduleRule{Start: RuleEvent{Action: ON, TimeOpt: SUNRISE, Minutes: 60}, Days: weekdays}, false},
+		{ScheduleRule{Start: RuleEvent{Action: 2}, Days: weekdays}, false},
+		{ScheduleRule{Start: RuleEvent{Action: ON, TimeOpt: 3}, Days: weekdays}, false},
+		{ScheduleRule{Start: RuleEvent{Action: ON, Minutes: 60}}, false},
+		{ScheduleRule{Start: RuleEvent{Action: ON, Minutes: 60}, End: &RuleEvent{Action: OFF, Minutes: 60}, Days: weekdays}, false},
+	}
+
+	for i, v := range tt {
+		if err := v.rule.validate(); (err == nil) != v.valid {
+			t.Errorf("%d: unexpected error %v", i, err)
+		}
+	}
+}
+
+func TestAddRule(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		GET_INFO: `{"system":{"get_sysinfo":{"latitude_i":407128,"longitude_i":-740060,"err_code":0}}}`,
+		`{"schedule":{"add_rule":{"stime_opt":2,"wday":[0,1,0,0,0,0,0],"smin":0,"soffset":-30,"enable":1,"repeat":1,"etime_opt":0,"name":"evening","eact":0,"month":0,"sact":1,"year":0,"longitude":-74.006,"day":0,"force":0,"latitude":40.7128,"emin":1380},"set_overall_enable":{"enable":1}}}`: `{"schedule":{"add_rule":{"id":"8AA7FA6E4F2B1D0DC6D6A3AF8AE28EBC","err_code":0},"set_overall_enable":{"err_code":0}}}`,
+		GET_SCHEDULE_RULES_LIST: `{"schedule":{"get_rules":{"rule_list":[{"id":"8AA7FA6E4F2B1D0DC6D6A3AF8AE28EBC","name":"evening","enable":1,"wday":[0,1,0,0,0,0,0],"stime_opt":2,"smin":1110,"soffset":-30,"sact":1,"etime_opt":0,"emin":1380,"eact":0,"repeat":1,"year":0,"month":0,"day":0}],"enable":1,"err_code":0}}}`,
+	}}
+	p := NewHS100("10.0.0.1", time.Second, WithTransport(f))
+
+	rule := ScheduleRule{
+		Name:    "evening",
+		Enabled: true,
+		Start:   RuleEvent{Action: ON, TimeOpt: SUNSET, Offset: -30},
+		End:     &RuleEvent{Action: OFF, Minutes: 23 * 60},
+		Days:    Days{Monday: true},
+	}
+	id, err := p.AddRule(rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "8AA7FA6E4F2B1D0DC6D6A3AF8AE28EBC" {
+		t.Errorf("unexpected id %q", id)
+	}
+
+	rules, err := p.GetScheduleList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expecting := rule
+	expecting.ID = id
+	if len(rules) != 1 || !reflect.DeepEqual(rules[0], expecting) {
+		t.Errorf("expecting %+v; got %+v", expecting, rules)
+	}
+
+	f.sent = nil
+	if err := p.UpdateRule(rule); err == nil {
+		t.Error("expecting an error for a rule without ID")
+	}
+	rule.Start.Minutes = 10
+	if _, err := p.AddRule(rule); err == nil {
+		t.Error("expecting an error for minutes on a sunset rule")
+	}
+	if len(f.sent) != 0 {
+		t.Errorf("expecting no request; got %v", f.sent)
+	}
+}
+
+func TestRuleEnd(t *testing.T) {
+	tt := []struct {
+		data      string
+		expecting *RuleEvent
+	}{
+		{`{"id":"A","sact":1,"stime_opt":0,"smin":1080,"etime_opt":0,"eact":0,"emin":1380,"repeat":1,"wday":[1,1,1,1,1,1,1]}`, &RuleEvent{Action: OFF, Minutes: 1380}},
+		{`{"id":"B","sact":1,"stime_opt":0,"smin":1080,"etime_opt":1,"eact":0,"emin":410,"eoffset":15,"repeat":1,"wday":[1,1,1,1,1,1,1]}`, &RuleEvent{Action: OFF, TimeOpt: SUNRISE, Offset: 15}},
+		{`{"id":"C","sact":1,"stime_opt":0,"smin":1080,"etime_opt":-1,"eact":-1,"emin":0,"repeat":1,"wday":[1,1,1,1,1,1,1]}`, nil},
+		{`{"id":"D","sact":1,"stime_opt":0,"smin":1080,"repeat":1,"wday":[1,1,1,1,1,1,1]}`, nil},
+	}
+
+	for _, v := range tt {
+		var r Rule
+		if err := json.Unmarshal([]byte(v.data), &r); err != nil {
+			t.Fatal(err)
+		}
+
+		rule := r.ScheduleRule()
+		if !reflect.DeepEqual(rule.End, v.expecting) {
+			t.Errorf("%s: expecting end %+v; got %+v", r.Id, v.expecting, rule.End)
+		}
+
+		// converting back must send the same end
+		params, err := rule.params()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if params.EndTimeOpt != int(r.EndTimeOpt) || params.EndAction != int(r.EndAction) {
+			t.Errorf("%s: expecting end %d/%d; got %d/%d", r.Id, r.EndTimeOpt, r.EndAction, params.EndTimeOpt, params.EndAction)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package tplink
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrNoSunriseSunset is returned when the sun does not rise or set on a day, near the poles
+var ErrNoSunriseSunset = errors.New("no sunrise or sunset on that day")
+
+// Sunrise and sunset on the day of date, in its location, at the given coordinates (degrees, east and north positive).
+// Uses the NOAA general solar position equations, accurate to a couple of minutes.
+func SunriseSunset(date time.Time, latitude float64, longitude float64) (time.Time, time.Time, error) {
+	// minutes after midnight UTC of the same calendar day
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	// fractional year at noon, in radians
+	g := 2 * math.Pi / 365 * float64(day.YearDay()-1)
+	eqtime := 229.18 * (0.000075 + 0.001868*math.Cos(g) - 0.032077*math.Sin(g) - 0.014615*math.Cos(2*g) - 0.040849*math.Sin(2*g))
+	decl := 0.006918 - 0.399912*math.Cos(g) + 0.070257*math.Sin(g) - 0.006758*math.Cos(2*g) + 0.000907*math.Sin(2*g) - 0.002697*math.Cos(3*g) + 0.00148*math.Sin(3*g)
+
+	lat := latitude * math.Pi / 180
+	// 90.833 degrees accounts for the refraction and the size of the sun disc
+	cosHA := math.Cos(90.833*math.Pi/180)/(math.Cos(lat)*math.Cos(decl)) - math.Tan(lat)*math.Tan(decl)
+	if cosHA < -1 || cosHA > 1 {
+		return time.Time{}, time.Time{}, ErrNoSunriseSunset
+	}
+	ha := math.Acos(cosHA) * 180 / math.Pi
+
+	at := func(minutes float64) time.Time {
+		return day.Add(time.Duration(minutes * float64(time.Minute))).Round(time.Second).In(date.Location())
+	}
+	return at(720 - 4*(longitude+ha) - eqtime), at(720 - 4*(longitude-ha) - eqtime), nil
+}
+
+// When the event fires on the day of date, in its location, at the given coordinates
+func (e RuleEvent) At(date time.Time, latitude float64, longitude float64) (time.Time, error) {
+	if e.TimeOpt == NONE {
+		midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+		return midnight.Add(time.Duration(e.Minutes) * time.Minute), nil
+	}
+
+	sunrise, sunset, err := SunriseSunset(date, latitude, longitude)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t := sunset
+	if e.TimeOpt == SUNRISE {
+		t = sunrise
+	}
+	return t.Add(time.Duration(e.Offset) * time.Minute), nil
+}
+
+// Previews when the rule starts and ends (zero when it has no end) on the day of date,
+// at the location and in the time zone of the device
+func (p *HS100) PreviewRule(rule ScheduleRule, date time.Time) (time.Time, time.Time, error) {
+	return p.PreviewRuleContext(context.Background(), rule, date)
+}
+
+func (p *HS100) PreviewRuleContext(ctx context.Context, rule ScheduleRule, date time.Time) (time.Time, time.Time, error) {
+	info, err := p.InfoContext(ctx)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	loc, err := p.TimeZoneContext(ctx)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	latitude, longitude := info.Location()
+	date = time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, loc)
+
+	start, err := rule.Start.At(date, latitude, longitude)
+	if err != nil || rule.End == nil {
+		return start, time.Time{}, err
+	}
+
+	end, err := rule.End.At(date, latitude, longitude)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// sunParams fills the device location into sunrise and sunset rules, instead of 0,0
+func (p *HS100) sunParams(ctx context.Context, params *ruleParams) error {
+	if params.StartTimeOpt == NONE && params.EndTimeOpt != int(SUNRISE) && params.EndTimeOpt != int(SUNSET) {
+		return nil
+	}
+
+	info, err := p.InfoContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	params.Latitude, params.Longitude = info.Location()
+	return nil
+}
@@ -0,0 +1,77 @@
+package tplink
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSunriseSunset(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	london, _ := time.LoadLocation("Europe/London")
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	tt := []struct {
+		date                time.Time
+		latitude, longitude float64
+		sunrise, sunset     string
+	}{
+		{time.Date(2024, 6, 21, 0, 0, 0, 0, newYork), 40.7128, -74.0060, "05:25", "20:31"},
+		{time.Date(2024, 12, 21, 0, 0, 0, 0, london), 51.5074, -0.1278, "08:04", "15:53"},
+		{time.Date(2024, 6, 1, 0, 0, 0, 0, tokyo), 35.6762, 139.6503, "04:27", "18:52"},
+	}
+
+	for _, v := range tt {
+		sunrise, sunset, err := SunriseSunset(v.date, v.latitude, v.longitude)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, c := range []struct {
+			got       time.Time
+			expecting string
+		}{{sunrise, v.sunrise}, {sunset, v.sunset}} {
+			expecting, _ := time.ParseInLocation("2006-01-02 15:04", v.date.Format("2006-01-02 ")+c.expecting, v.date.Location())
+			if d := c.got.Sub(expecting); d < -3*time.Minute || d > 3*time.Minute {
+				t.Errorf("%s: expecting %s; got %s", v.date.Location(), expecting, c.got)
+			}
+		}
+	}
+
+	if _, _, err := SunriseSunset(time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC), 69.6492, 18.9553); !errors.Is(err, ErrNoSunriseSunset) {
+		t.Errorf("expecting %v; got %v", ErrNoSunriseSunset, err)
+	}
+}
+
+func TestPreviewRule(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		GET_INFO:     `{"system":{"get_sysinfo":{"latitude":40.7128,"longitude":-74.006,"err_code":0}}}`,
+		GET_TIMEZONE: `{"time":{"get_timezone":{"index":18,"err_code":0}}}`,
+		`{"system":{"set_dev_location":{"latitude":40.7128,"longitude":-74.006,"latitude_i":407128,"longitude_i":-740060}}}`: `{"system":{"set_dev_location":{"err_code":0}}}`,
+	}}
+	p := NewHS100("10.0.0.1", time.Second, WithTransport(f))
+
+	if err := p.SetLocation(40.7128, -74.006); err != nil {
+		t.Error(err)
+	}
+	if err := p.SetLocation(91, 0); err == nil {
+		t.Error("expecting an error for latitude 91")
+	}
+
+	rule := ScheduleRule{
+		Start: RuleEvent{Action: ON, TimeOpt: SUNSET, Offset: -30},
+		End:   &RuleEvent{Action: OFF, Minutes: 23 * 60},
+		Days:  Days{Friday: true},
+	}
+	start, end, err := p.PreviewRule(rule, time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if start.Format("15:04") < "19:58" || start.Format("15:04") > "20:04" || start.Location().String() != "America/Indiana/Indianapolis" {
+		t.Errorf("expecting about 20:01; got %s", start)
+	}
+	if end.Format("2006-01-02 15:04") != "2024-06-21 23:00" {
+		t.Errorf("expecting 23:00; got %s", end)
+	}
+}
@@ -0,0 +1,186 @@
+package tplink
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrUnknownTimeZone is returned for time zones missing from the device time zone table
+var ErrUnknownTimeZone = errors.New("time zone not supported by the device")
+
+// Device time zone indexes, as used by get_timezone and set_timezone, mapped to IANA names.
+// Transcribed from the TIMEZONE_INDEX table of python-kasa (kasa/iot/iottimezone.py), itself
+// taken from the zone list of the Kasa app. Deprecated link names of the source are replaced
+// by the zone they link to, e.g. US/Hawaii is Pacific/Honolulu and America/Godthab is America/Nuuk.
+var timeZones = [...]string{
+	0:   "Etc/GMT+12",
+	1:   "Pacific/Pago_Pago",
+	2:   "Pacific/Honolulu",
+	3:   "America/Anchorage",
+	4:   "America/Tijuana",
+	5:   "Etc/GMT+8",
+	6:   "America/Los_Angeles",
+	7:   "America/Phoenix",
+	8:   "America/Mazatlan",
+	9:   "MST",
+	10:  "America/Denver",
+	11:  "America/Mexico_City",
+	12:  "Etc/GMT+6",
+	13:  "America/Chicago",
+	14:  "America/Monterrey",
+	15:  "America/Regina",
+	16:  "America/Bogota",
+	17:  "EST",
+	18:  "America/Indiana/Indianapolis",
+	19:  "America/Caracas",
+	20:  "America/Asuncion",
+	21:  "Etc/GMT+4",
+	22:  "America/Halifax",
+	23:  "America/Cuiaba",
+	24:  "America/Manaus",
+	25:  "America/Santiago",
+	26:  "America/St_Johns",
+	27:  "America/Sao_Paulo",
+	28:  "America/Argentina/Buenos_Aires",
+	29:  "America/Cayenne",
+	30:  "America/Miquelon",
+	31:  "America/Montevideo",
+	32:  "America/Santiago",
+	33:  "America/Nuuk",
+	34:  "Etc/GMT+2",
+	35:  "Atlantic/Azores",
+	36:  "Atlantic/Cape_Verde",
+	37:  "Africa/Casablanca",
+	38:  "UTC",
+	39:  "Europe/London",
+	40:  "Africa/Monrovia",
+	41:  "Europe/Amsterdam",
+	42:  "Europe/Belgrade",
+	43:  "Europe/Brussels",
+	44:  "Europe/Sarajevo",
+	45:  "Africa/Lagos",
+	46:  "Africa/Windhoek",
+	47:  "Asia/Amman",
+	48:  "Europe/Athens",
+	49:  "Asia/Beirut",
+	50:  "Africa/Cairo",
+	51:  "Asia/Damascus",
+	52:  "EET",
+	53:  "Africa/Harare",
+	54:  "Europe/Helsinki",
+	55:  "Europe/Istanbul",
+	56:  "Asia/Jerusalem",
+	57:  "Europe/Kaliningrad",
+	58:  "Africa/Tripoli",
+	59:  "Asia/Baghdad",
+	60:  "Asia/Riyadh",
+	61:  "Europe/Minsk",
+	62:  "Europe/Moscow",
+	63:  "Africa/Nairobi",
+	64:  "Asia/Tehran",
+	65:  "Asia/Dubai",
+	66:  "Asia/Baku",
+	67:  "Europe/Samara",
+	68:  "Indian/Mauritius",
+	69:  "Asia/Tbilisi",
+	70:  "Asia/Yerevan",
+	71:  "Asia/Kabul",
+	72:  "Asia/Ashgabat",
+	73:  "Asia/Yekaterinburg",
+	74:  "Asia/Karachi",
+	75:  "Asia/Kolkata",
+	76:  "Asia/Colombo",
+	77:  "Asia/Kathmandu",
+	78:  "Asia/Almaty",
+	79:  "Asia/Dhaka",
+	80:  "Asia/Novosibirsk",
+	81:  "Asia/Yangon",
+	82:  "Asia/Bangkok",
+	83:  "Asia/Krasnoyarsk",
+	84:  "Asia/Shanghai",
+	85:  "Asia/Irkutsk",
+	86:  "Asia/Singapore",
+	87:  "Australia/Perth",
+	88:  "Asia/Taipei",
+	89:  "Asia/Ulaanbaatar",
+	90:  "Asia/Tokyo",
+	91:  "Asia/Seoul",
+	92:  "Asia/Yakutsk",
+	93:  "Australia/Adelaide",
+	94:  "Australia/Darwin",
+	95:  "Australia/Brisbane",
+	96:  "Australia/Sydney",
+	97:  "Pacific/Guam",
+	98:  "Australia/Hobart",
+	99:  "Antarctica/DumontDUrville",
+	100: "Asia/Magadan",
+	101: "Asia/Srednekolymsk",
+	102: "Etc/GMT-11",
+	103: "Asia/Anadyr",
+	104: "Pacific/Auckland",
+	105: "Etc/GMT-12",
+	106: "Pacific/Fiji",
+	107: "Etc/GMT-13",
+	108: "Pacific/Apia",
+	109: "Etc/GMT-14",
+}
+
+var (
+	timeZonesOnce sync.Once
+	timeZoneLocs  [len(timeZones)]*time.Location // nil when missing from the system time zone database
+)
+
+func loadTimeZones() {
+	timeZonesOnce.Do(func() {
+		for i, name := range timeZones {
+			timeZoneLocs[i], _ = time.LoadLocation(name)
+		}
+	})
+}
+
+// Gets the location of a device time zone index
+func TimeZoneLocation(index int) (*time.Location, error) {
+	if index < 0 || index >= len(timeZones) {
+		return nil, fmt.Errorf("time zone index %d: %w", index, ErrUnknownTimeZone)
+	}
+
+	loadTimeZones()
+	if timeZoneLocs[index] == nil {
+		return nil, fmt.Errorf("failed to load time zone %s for index %d", timeZones[index], index)
+	}
+	return timeZoneLocs[index], nil
+}
+
+// Gets the device time zone index of a location, by name or else by the first zone
+// with the same UTC offsets all year round (e.g. America/New_York is America/Indiana/Indianapolis)
+func TimeZoneIndex(loc *time.Location) (int, error) {
+	loadTimeZones()
+	for i, name := range timeZones {
+		if name == loc.String() && timeZoneLocs[i] != nil {
+			return i, nil
+		}
+	}
+
+	year := time.Now().Year()
+	for i, l := range timeZoneLocs {
+		if l != nil && sameOffsets(loc, l, year) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%s: %w", loc, ErrUnknownTimeZone)
+}
+
+// sameOffsets compares the UTC offsets of two locations every day of year
+func sameOffsets(a *time.Location, b *time.Location, year int) bool {
+	t := time.Date(year, time.January, 1, 12, 0, 0, 0, time.UTC)
+	for ; t.Year() == year; t = t.AddDate(0, 0, 1) {
+		_, x := t.In(a).Zone()
+		_, y := t.In(b).Zone()
+		if x != y {
+			return false
+		}
+	}
+	return true
+}
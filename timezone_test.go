@@ -0,0 +1,111 @@
+package tplink
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeZoneTable(t *testing.T) {
+	for i, name := range timeZones {
+		loc, err := TimeZoneLocation(i)
+		if err != nil {
+			t.Errorf("index %d: %v", i, err)
+			continue
+		}
+
+		index, err := TimeZoneIndex(loc)
+		if err != nil || timeZones[index] != name {
+			t.Errorf("%s: expecting index %d; got %d, %v", name, i, index, err)
+		}
+	}
+
+	if _, err := TimeZoneLocation(len(timeZones)); !errors.Is(err, ErrUnknownTimeZone) {
+		t.Errorf("expecting %v; got %v", ErrUnknownTimeZone, err)
+	}
+}
+
+// Indexes devices report once set to well known zones, checked against the UTC offsets of that zone,
+// so that a shifted table entry is caught
+func TestTimeZoneKnownIndexes(t *testing.T) {
+	tt := []struct {
+		index int
+		zone  string
+	}{
+		{6, "America/Los_Angeles"},
+		{13, "America/Chicago"},
+		{18, "America/New_York"},
+		{38, "UTC"},
+		{39, "Europe/London"},
+		{41, "Europe/Berlin"},
+		{90, "Asia/Tokyo"},
+		{104, "Pacific/Auckland"},
+	}
+
+	for _, v := range tt {
+		expecting, err := time.LoadLocation(v.zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loc, err := TimeZoneLocation(v.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, month := range []time.Month{time.January, time.July} {
+			d := time.Date(2024, month, 15, 12, 0, 0, 0, time.UTC)
+			_, x := d.In(loc).Zone()
+			_, y := d.In(expecting).Zone()
+			if x != y {
+				t.Errorf("index %d in %s: expecting the offset of %s (%d); got %s (%d)", v.index, month, v.zone, y, loc, x)
+			}
+		}
+	}
+}
+
+func TestTimeZoneIndex(t *testing.T) {
+	tt := []struct {
+		zone      string
+		expecting int
+	}{
+		{"America/New_York", 18},
+		{"Europe/Paris", 41},
+		{"Asia/Hong_Kong", 84},
+	}
+
+	for _, v := range tt {
+		loc, err := time.LoadLocation(v.zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if index, err := TimeZoneIndex(loc); err != nil || index != v.expecting {
+			t.Errorf("%s: expecting %d; got %d, %v", v.zone, v.expecting, index, err)
+		}
+	}
+
+	if _, err := TimeZoneIndex(time.FixedZone("", 5*3600+15*60+30)); !errors.Is(err, ErrUnknownTimeZone) {
+		t.Errorf("expecting %v; got %v", ErrUnknownTimeZone, err)
+	}
+}
+
+func TestTime(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		`{"time":{"get_time":{},"get_timezone":null}}`:                                                   `{"time":{"get_time":{"year":2018,"month":3,"mday":4,"hour":5,"min":6,"sec":7,"err_code":0},"get_timezone":{"index":39,"err_code":0}}}`,
+		`{"time":{"set_timezone":{"year":2018,"month":7,"mday":4,"hour":5,"min":6,"sec":7,"index":43}}}`: `{"time":{"set_timezone":{"err_code":0}}}`,
+	}}
+	p := NewHS100("10.0.0.1", time.Second, WithTransport(f))
+
+	d, err := p.Time()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Location().String() != "Europe/London" || d.Hour() != 5 {
+		t.Errorf("unexpected time %s", d)
+	}
+
+	brussels, _ := time.LoadLocation("Europe/Brussels")
+	if err := p.SetTime(time.Date(2018, 7, 4, 5, 6, 7, 0, brussels)); err != nil {
+		t.Error(err)
+	}
+}
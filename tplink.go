@@ -1,7 +1,8 @@
 package tplink
 
 import (
-	"bufio"
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -41,37 +42,49 @@ type Days struct {
 }
 
 func (d Days) String() string {
-	days := []string{"0", "0", "0", "0", "0", "0", "0"}
+	days := []string{}
+	for _, v := range d.weekdays() {
+		days = append(days, strconv.Itoa(v))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(days, ","))
+}
+
+// weekdays returns the days as sent to the device, a list of 7 flags starting on Sunday
+func (d Days) weekdays() []int {
+	days := []int{0, 0, 0, 0, 0, 0, 0}
 	if d.Sunday {
-		days[time.Sunday] = "1"
+		days[time.Sunday] = 1
 	}
 
 	if d.Monday {
-		days[time.Monday] = "1"
+		days[time.Monday] = 1
 	}
 
 	if d.Tuesday {
-		days[time.Tuesday] = "1"
+		days[time.Tuesday] = 1
 	}
 
 	if d.Wednesday {
-		days[time.Wednesday] = "1"
+		days[time.Wednesday] = 1
 	}
 
 	if d.Thursday {
-		days[time.Thursday] = "1"
+		days[time.Thursday] = 1
 	}
 
 	if d.Friday {
-		days[time.Friday] = "1"
+		days[time.Friday] = 1
 	}
 
 	if d.Saturday {
-		days[time.Saturday] = "1"
+		days[time.Saturday] = 1
 	}
-	return fmt.Sprintf("[%s]", strings.Join(days, ","))
+	return days
 }
 
+// Raw commands as documented by softScheck, kept for reference. The library builds
+// its requests from typed structs (see request.go) so that user input is escaped;
+// the templates below must not be filled with untrusted strings.
 const (
 	// --- Plug HS100 and HS110 ---
 
@@ -107,17 +120,24 @@ const (
 	//  --- HS110 only ---
 
 	// EMeter Energy Usage Statistics Commands
-	GET_METER         = `{"system":{"get_sysinfo":{}}, "emeter":{"get_realtime":{},"get_vgain_igain":{}}}`
+	GET_METER         = `{"emeter":{"get_realtime":{}}}`
 	GET_DAILY_STATS   = `{"emeter":{"get_daystat":{"month":%d,"year":%d}}}`
 	GET_MONTHLY_STATS = `{"emeter":{"get_monthstat":{"year":%d}}}`
 	ERASE_ALL_STATS   = `{"emeter":{"erase_emeter_stat":null}}`
 )
 
-type Device struct {
+// A device found by Scan
+type ScanResult struct {
 	IPAddress string
 	Info      Info
 }
 
+// Returns the type matching the model of the device, as Connect does
+func (d ScanResult) Device(opts ...Option) (Device, error) {
+	info := d.Info
+	return newDevice(newClient(d.IPAddress, DefaultTimeout, opts...), &info)
+}
+
 type Response struct {
 	System struct {
 		*Info    `json:"get_sysinfo"`
@@ -129,6 +149,11 @@ type Response struct {
 			ErrorCode    int    `json:"err_code"`
 			ErrorMessage string `json:"err_msg"`
 		} `json:"set_relay_state"`
+		DownloadState struct {
+			DownloadState
+			ErrorCode    int    `json:"err_code"`
+			ErrorMessage string `json:"err_msg"`
+		} `json:"get_download_state"`
 	}
 
 	CNCloud struct {
@@ -206,6 +231,25 @@ type Response struct {
 		} `json:"delete_all_rules"`
 	} `json:"schedule"`
 
+	Countdown struct {
+		Rule struct {
+			List []CountdownRule `json:"rule_list"`
+		} `json:"get_rules"`
+		AddRule struct {
+			ID string `json:"id"`
+		} `json:"add_rule"`
+	} `json:"count_down"`
+
+	AntiTheft struct {
+		Rule struct {
+			List   []AwayRule `json:"rule_list"`
+			Enable int        `json:"enable"`
+		} `json:"get_rules"`
+		AddRule struct {
+			ID string `json:"id"`
+		} `json:"add_rule"`
+	} `json:"anti_theft"`
+
 	NetIf struct {
 		GetScanInfo struct {
 			List         []AP   `json:"ap_list"`
@@ -219,8 +263,22 @@ type Response struct {
 		} `json:"set_stainfo"`
 	} `json:"netif"`
 
+	Dimmer struct {
+		Parameters *DimmerParameters `json:"get_dimmer_parameters"`
+	} `json:"smartlife.iot.dimmer"`
+
+	Lighting struct {
+		GetLightState        *LightState `json:"get_light_state"`
+		TransitionLightState *LightState `json:"transition_light_state"`
+	} `json:"smartlife.iot.smartbulb.lightingservice"`
+
+	LightStrip struct {
+		GetLightState *LightState `json:"get_light_state"`
+	} `json:"smartlife.iot.lightStrip"`
+
 	EMeter struct {
-		*Meter         `json:"get_realtime"`
+		Meter          *Meter       `json:"get_realtime"`
+		VGainIGain     *Gains       `json:"get_vgain_igain"`
 		MonthlyStats   *MonthyStats `json:"get_monthstat"`
 		DailyStats     *DailyStats  `json:"get_daystat"`
 		EraseMeterStat struct {
@@ -250,6 +308,13 @@ type Info struct {
 	LedOff          int     `json:"led_off"`     // 0 = Led ON (default); 1 = Led OFF
 	Latitude        float64 `json:"latitude"`    // Optional Geolocation information
 	Longitude       float64 `json:"longitude"`   // Optional Geolocation information
+	LatitudeI       int     `json:"latitude_i"`  // Geolocation in 1/10000 degree, newer firmwares
+	LongitudeI      int     `json:"longitude_i"` // Geolocation in 1/10000 degree, newer firmwares
+	Brightness      int     `json:"brightness"`  // Brightness of a dimmer, 1 to 100
+	MicType         string  `json:"mic_type"`    // Type reported by smart bulbs, e.g. "IOT.SMARTBULB"
+	MicMac          string  `json:"mic_mac"`     // Mac Address reported by smart bulbs
+	ChildNum        int     `json:"child_num"`   // Number of outlets of a power strip
+	Children        []Child `json:"children"`    // Outlets of a power strip
 }
 
 func (i Info) IsOn() bool {
@@ -260,6 +325,34 @@ func (i Info) IsLedOn() bool {
 	return i.LedOff == 0
 }
 
+// Latitude and longitude of the device, in degrees, whichever format the firmware reports
+func (i Info) Location() (float64, float64) {
+	if i.Latitude == 0 && i.Longitude == 0 {
+		return float64(i.LatitudeI) / 10000, float64(i.LongitudeI) / 10000
+	}
+	return i.Latitude, i.Longitude
+}
+
+func (i Info) IsBulb() bool {
+	return i.MicType == "IOT.SMARTBULB"
+}
+
+func (i Info) Features() Features {
+	return ParseFeatures(i.Feature)
+}
+
+// An outlet of a power strip
+type Child struct {
+	ID     string `json:"id"`      // Child ID, either the full ID or the outlet index appended to the strip device ID
+	Alias  string `json:"alias"`   // Description. e.g "Heater"
+	State  int    `json:"state"`   // State:  0 = OFF; 1 = ON
+	OnTime int    `json:"on_time"` // Seconds since the outlet was turned on
+}
+
+func (c Child) IsOn() bool {
+	return c.State == 1
+}
+
 type Cloud struct {
 	Username string `json:"username"`
 	Server   string `json:"server"`
@@ -270,11 +363,54 @@ func (c Cloud) isBinded() bool {
 	return c.Binded == 1
 }
 
+// Realtime reading of the energy meter. Older firmwares report A, V, W and kWh while newer
+// ones (HS110 v2+, KP115, KP125) report mA, mV, mW and Wh; both are normalized to the former.
 type Meter struct {
-	Current float64 `json:"current"`
-	Voltage float64 `json:"voltage"`
-	Power   float64 `json:"power"`
-	Total   float64 `json:"total"`
+	Current float64 `json:"current"` // Ampere
+	Voltage float64 `json:"voltage"` // Volt
+	Power   float64 `json:"power"`   // Watt
+	Total   float64 `json:"total"`   // kWh since the statistics were erased
+}
+
+func (m *Meter) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Current   *float64 `json:"current"`
+		Voltage   *float64 `json:"voltage"`
+		Power     *float64 `json:"power"`
+		Total     *float64 `json:"total"`
+		CurrentMA *float64 `json:"current_ma"`
+		VoltageMV *float64 `json:"voltage_mv"`
+		PowerMW   *float64 `json:"power_mw"`
+		TotalWH   *float64 `json:"total_wh"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*m = Meter{
+		Current: normalize(raw.Current, raw.CurrentMA),
+		Voltage: normalize(raw.Voltage, raw.VoltageMV),
+		Power:   normalize(raw.Power, raw.PowerMW),
+		Total:   normalize(raw.Total, raw.TotalWH),
+	}
+	return nil
+}
+
+// normalize returns v when reported, milli (a value in thousandths of the unit) otherwise
+func normalize(v *float64, milli *float64) float64 {
+	if v != nil {
+		return *v
+	}
+	if milli != nil {
+		return *milli / 1000
+	}
+	return 0
+}
+
+// Voltage and current gains of the energy meter
+type Gains struct {
+	VGain int `json:"vgain"`
+	IGain int `json:"igain"`
 }
 
 type MonthyStats struct {
@@ -282,9 +418,25 @@ type MonthyStats struct {
 }
 
 type MonthlyUsage struct {
-	Year   int
-	Month  int
-	Energy float64
+	Year   int     `json:"year"`
+	Month  int     `json:"month"`
+	Energy float64 `json:"energy"` // kWh
+}
+
+// Energy is reported in kWh ("energy") or Wh ("energy_wh") depending on the firmware
+func (u *MonthlyUsage) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Year     int      `json:"year"`
+		Month    int      `json:"month"`
+		Energy   *float64 `json:"energy"`
+		EnergyWH *float64 `json:"energy_wh"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*u = MonthlyUsage{Year: raw.Year, Month: raw.Month, Energy: normalize(raw.Energy, raw.EnergyWH)}
+	return nil
 }
 
 type DailyStats struct {
@@ -292,10 +444,27 @@ type DailyStats struct {
 }
 
 type DailyUsage struct {
-	Year   int
-	Month  int
-	Day    int
-	Energy float64
+	Year   int     `json:"year"`
+	Month  int     `json:"month"`
+	Day    int     `json:"day"`
+	Energy float64 `json:"energy"` // kWh
+}
+
+// Energy is reported in kWh ("energy") or Wh ("energy_wh") depending on the firmware
+func (u *DailyUsage) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Year     int      `json:"year"`
+		Month    int      `json:"month"`
+		Day      int      `json:"day"`
+		Energy   *float64 `json:"energy"`
+		EnergyWH *float64 `json:"energy_wh"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*u = DailyUsage{Year: raw.Year, Month: raw.Month, Day: raw.Day, Energy: normalize(raw.Energy, raw.EnergyWH)}
+	return nil
 }
 
 type AP struct {
@@ -315,6 +484,67 @@ type Rule struct {
 	Month    int        `json:"month"`
 	Day      int        `json:"day"`
 	TimeOpt  TimeOption `json:"stime_opt"` // If set, means that this rule will run on sunrise or sunset
+	Offset   int        `json:"soffset"`   // minutes from sunrise or sunset
+
+	// -1 when the rule has no end
+	EndTimeOpt TimeOption `json:"etime_opt"`
+	EndAction  Action     `json:"eact"`
+	EndMinutes int        `json:"emin"`
+	EndOffset  int        `json:"eoffset"`
+}
+
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	type rule Rule
+	raw := rule{EndTimeOpt: -1, EndAction: -1} // missing from start only rules on some firmwares
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*r = Rule(raw)
+	return nil
+}
+
+// Progress of a firmware download, Status is negative when the download failed
+type DownloadState struct {
+	Status     int `json:"status"`
+	Ratio      int `json:"ratio"`       // percent downloaded
+	RebootTime int `json:"reboot_time"` // seconds
+	FlashTime  int `json:"flash_time"`  // seconds
+}
+
+// An away mode rule, randomly turning the device on and off between StartMinutes and EndMinutes
+type AwayRule struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Enable       int      `json:"enable"`
+	StartMinutes int      `json:"smin"` // minutes after midnight
+	EndMinutes   int      `json:"emin"`
+	Frequency    int      `json:"frequency"` // times the device is turned on within the window
+	Duration     int      `json:"duration"`  // minutes the device stays on each time
+	Repeat       int      `json:"repeat"`
+	WeekDays     []Action `json:"wday"`
+}
+
+func (r AwayRule) IsEnabled() bool {
+	return r.Enable == 1
+}
+
+// A countdown timer, running Action once Delay has elapsed
+type CountdownRule struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Enable    int    `json:"enable"`
+	Delay     int    `json:"delay"` // seconds
+	Action    Action `json:"act"`
+	Remaining int    `json:"remain"` // seconds left before the action, when enabled
+}
+
+func (r CountdownRule) IsEnabled() bool {
+	return r.Enable == 1
+}
+
+func (r CountdownRule) RemainingTime() time.Duration {
+	return time.Duration(r.Remaining) * time.Second
 }
 
 type NextAction struct {
@@ -339,70 +569,64 @@ func decrypt(request []byte) string {
 	return string(result)
 }
 
+// encrypt returns the obfuscated request as sent over UDP, without the length header.
 func encrypt(s string) []byte {
+	return encryptWithHeader(s)[4:]
+}
+
+// encryptWithHeader returns the obfuscated request prefixed with its 4 byte big-endian length,
+// as expected by the devices on TCP.
+func encryptWithHeader(s string) []byte {
 	request := []byte(s)
 	key := byte(0xAB)
 	result := make([]byte, 4+len(request))
-	result[0] = 0x0
-	result[1] = 0x0
-	result[2] = 0x0
-	result[3] = 0x0
+	binary.BigEndian.PutUint32(result, uint32(len(request)))
 	for i, c := range request {
 		var a = key ^ uint8(c)
 		key = uint8(a)
 		result[i+4] = a
 	}
-	return result[4:]
+	return result
 }
 
-func exec(ip string, cmd string, timeout time.Duration) (string, error) {
-	data := encrypt(cmd)
-	port := 9999
-	conn, err := net.DialTimeout("udp4", ip+":"+strconv.Itoa(port), timeout)
-	if err != nil {
-		return "", err
-	}
-	defer conn.Close()
-	_, err = conn.Write(data)
-	if err != nil {
-		return "", err
-	}
-	rData := make([]byte, 1500)
-	rLen, err := bufio.NewReader(conn).Read(rData)
-	if err != nil {
-		return "", err
-	}
-
-	return decrypt(rData[:rLen]), nil
+// Scan broadcasts a discovery request and collects the replies received within timeout
+func Scan(timeout time.Duration) ([]ScanResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return ScanContext(ctx)
 }
 
-func Scan(timeout time.Duration) ([]Device, error) {
-	devices := []Device{}
+// ScanContext broadcasts a discovery request and collects replies until ctx is done.
+// Reaching the ctx deadline ends the scan normally; a cancellation returns the devices found so far along with ctx.Err().
+func ScanContext(ctx context.Context) ([]ScanResult, error) {
+	devices := []ScanResult{}
 
 	broadcastAddr, err := net.ResolveUDPAddr("udp", "255.255.255.255:9999")
 	if err != nil {
 		return nil, err
 	}
 
-	fromAddr, err := net.ResolveUDPAddr("udp", "0.0.0.0:8755")
+	var lc net.ListenConfig
+	conn, err := lc.ListenPacket(ctx, "udp", "0.0.0.0:8755")
 	if err != nil {
 		return nil, err
 	}
-
-	sock, err := net.ListenUDP("udp", fromAddr)
+	sock := conn.(*net.UDPConn)
 	defer sock.Close()
-	if err != nil {
-		return nil, err
-	}
 	sock.SetReadBuffer(2048)
 
-	cmd := encrypt(GET_INFO)
-	_, err = sock.WriteToUDP(cmd, broadcastAddr)
-	if err != nil {
-		return nil, err
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := sock.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
 	}
+	stop := context.AfterFunc(ctx, func() {
+		sock.SetReadDeadline(time.Now())
+	})
+	defer stop()
 
-	err = sock.SetReadDeadline(time.Now().Add(timeout))
+	cmd := encrypt(GET_INFO)
+	_, err = sock.WriteToUDP(cmd, broadcastAddr)
 	if err != nil {
 		return nil, err
 	}
@@ -421,11 +645,18 @@ func Scan(timeout time.Duration) ([]Device, error) {
 			return nil, err
 		}
 
-		devices = append(devices, Device{
+		if r.System.Info == nil {
+			continue
+		}
+
+		devices = append(devices, ScanResult{
 			IPAddress: addr.IP.String(),
 			Info:      *r.System.Info,
 		})
 	}
 
-	return devices, err
+	if ctx.Err() == context.Canceled {
+		return devices, ctx.Err()
+	}
+	return devices, nil
 }
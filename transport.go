@@ -0,0 +1,233 @@
+package tplink
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Transport sends a JSON command to the device at ip and returns its JSON reply.
+// Implementations are responsible for the wire encoding and must give up as soon as ctx is done.
+type Transport interface {
+	Exec(ctx context.Context, ip string, cmd string) (string, error)
+}
+
+// Protocol selects how commands are sent to a device. Every Protocol is a Transport,
+// UDP being the default one.
+type Protocol int
+
+const (
+	UDP Protocol = iota
+	TCP
+	TCP_WITH_UDP_FALLBACK // try TCP first and fall back to UDP if the device can't be reached
+)
+
+const (
+	port = 9999
+	// upper bound for a TCP reply, guards against a corrupted length header
+	maxResponseSize = 16 << 20
+)
+
+// client holds the connection settings shared by all device types
+type client struct {
+	ip        string
+	timeout   time.Duration
+	transport Transport
+	retry     RetryPolicy
+	features  Features      // nil when unknown
+	childIDs  []string      // outlets addressed by the commands, power strips only
+	poll      time.Duration // interval between polls of long running operations, e.g. firmware upgrades
+}
+
+func newClient(ip string, timeout time.Duration, opts ...Option) client {
+	c := client{ip: ip, timeout: timeout, transport: UDP, poll: defaultPollInterval}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// IP address of the device
+func (c *client) Addr() string {
+	return c.ip
+}
+
+// exec sends cmd to the device, retrying it according to the retry policy when it is idempotent.
+// The client timeout applies to every attempt, on top of ctx.
+func (c *client) exec(ctx context.Context, cmd string) (string, error) {
+	attempts := 1
+	if c.retry.Attempts > 1 && isIdempotent(cmd) {
+		attempts = c.retry.Attempts
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var data string
+		data, err = c.execOnce(ctx, cmd)
+		if err == nil {
+			return data, nil
+		}
+
+		if attempt >= attempts || ctx.Err() != nil || !c.retry.retryable(err) {
+			return "", &NetworkError{Addr: c.ip, Err: err}
+		}
+
+		t := time.NewTimer(c.retry.delay(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return "", &NetworkError{Addr: c.ip, Err: ctx.Err()}
+		case <-t.C:
+		}
+	}
+}
+
+func (c *client) execOnce(ctx context.Context, cmd string) (string, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return c.transport.Exec(ctx, c.ip, cmd)
+}
+
+// Option configures a device created with one of the NewXXX constructors
+type Option func(*client)
+
+// Set the timeout of every command sent to the device
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *client) {
+		c.timeout = timeout
+	}
+}
+
+// Use t to talk to the device instead of the default UDP transport
+func WithTransport(t Transport) Option {
+	return func(c *client) {
+		c.transport = t
+	}
+}
+
+// Exec sends cmd to the device using the selected protocol
+func (proto Protocol) Exec(ctx context.Context, ip string, cmd string) (string, error) {
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
+	switch proto {
+	case TCP:
+		return execTCP(ctx, addr, cmd)
+	case TCP_WITH_UDP_FALLBACK:
+		return execTCPWithUDPFallback(ctx, addr, cmd)
+	default:
+		return execUDP(ctx, addr, cmd)
+	}
+}
+
+// dial connects to addr and ties the connection deadline to ctx,
+// so that a cancellation unblocks any pending read or write.
+func dial(ctx context.Context, network string, addr string) (net.Conn, func(), error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	return conn, func() {
+		stop()
+		conn.Close()
+	}, nil
+}
+
+// ctxError reports the context error instead of the i/o timeout it caused
+func ctxError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func execUDP(ctx context.Context, addr string, cmd string) (string, error) {
+	data := encrypt(cmd)
+	conn, closeConn, err := dial(ctx, "udp4", addr)
+	if err != nil {
+		return "", err
+	}
+	defer closeConn()
+	_, err = conn.Write(data)
+	if err != nil {
+		return "", ctxError(ctx, err)
+	}
+	rData := make([]byte, 1500)
+	rLen, err := bufio.NewReader(conn).Read(rData)
+	if err != nil {
+		return "", ctxError(ctx, err)
+	}
+
+	return decrypt(rData[:rLen]), nil
+}
+
+// execTCPWithUDPFallback resends cmd over UDP when TCP fails, unless cmd may have
+// reached the device already and is not safe to repeat, e.g. adding a rule.
+func execTCPWithUDPFallback(ctx context.Context, addr string, cmd string) (string, error) {
+	conn, closeConn, err := dial(ctx, "tcp4", addr)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctxError(ctx, err)
+		}
+		return execUDP(ctx, addr, cmd) // nothing was sent
+	}
+
+	data, err := exchangeTCP(ctx, conn, cmd)
+	closeConn()
+	if err != nil && ctx.Err() == nil && isIdempotent(cmd) {
+		return execUDP(ctx, addr, cmd)
+	}
+	return data, err
+}
+
+func execTCP(ctx context.Context, addr string, cmd string) (string, error) {
+	conn, closeConn, err := dial(ctx, "tcp4", addr)
+	if err != nil {
+		return "", err
+	}
+	defer closeConn()
+
+	return exchangeTCP(ctx, conn, cmd)
+}
+
+func exchangeTCP(ctx context.Context, conn net.Conn, cmd string) (string, error) {
+	_, err := conn.Write(encryptWithHeader(cmd))
+	if err != nil {
+		return "", ctxError(ctx, err)
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", ctxError(ctx, err)
+	}
+
+	length := binary.BigEndian.Uint32(header)
+	if length > maxResponseSize {
+		return "", fmt.Errorf("response too large: %d bytes", length)
+	}
+
+	rData := make([]byte, length)
+	if _, err := io.ReadFull(conn, rData); err != nil {
+		return "", ctxError(ctx, err)
+	}
+
+	return decrypt(rData), nil
+}
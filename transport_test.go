@@ -0,0 +1,216 @@
+package tplink
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTransport answers commands from a map and records what was sent.
+// The first len(errs) calls fail with the queued errors, err fails every call.
+type fakeTransport struct {
+	replies map[string]string
+	errs    []error
+	err     error
+	sent    []string
+}
+
+func (f *fakeTransport) Exec(ctx context.Context, ip string, cmd string) (string, error) {
+	f.sent = append(f.sent, cmd)
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return "", err
+	}
+	if f.err != nil {
+		return "", f.err
+	}
+
+	for k, v := range f.replies {
+		if canonical(k) == canonical(cmd) {
+			return v, nil
+		}
+	}
+	return "", nil
+}
+
+// canonical re-encodes a JSON document with sorted keys and no spaces
+func canonical(s string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestEncryptWithHeader(t *testing.T) {
+	e := encryptWithHeader(GET_INFO)
+	if l := binary.BigEndian.Uint32(e[:4]); int(l) != len(GET_INFO) {
+		t.Errorf("expecting length header %d; got %d", len(GET_INFO), l)
+	}
+
+	if d := decrypt(e[4:]); d != GET_INFO {
+		t.Errorf("expecting %s; got %s", GET_INFO, d)
+	}
+}
+
+func TestExecTCP(t *testing.T) {
+	// larger than a single UDP datagram
+	reply := `{"schedule":{"get_rules":{"rule_list":[` + strings.Repeat(`{"id":"X"},`, 500) + `{"id":"Y"}],"err_code":0}}}`
+
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		if decrypt(req) != GET_SCHEDULE_RULES_LIST {
+			return
+		}
+		conn.Write(encryptWithHeader(reply))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	data, err := execTCP(ctx, l.Addr().String(), GET_SCHEDULE_RULES_LIST)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data != reply {
+		t.Errorf("expecting %d bytes; got %d", len(reply), len(data))
+	}
+}
+
+func TestExecTCPWithUDPFallback(t *testing.T) {
+	// a device that reads the TCP request then drops the connection
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			header := make([]byte, 4)
+			if _, err := io.ReadFull(conn, header); err == nil {
+				io.ReadFull(conn, make([]byte, binary.BigEndian.Uint32(header)))
+			}
+			conn.Close()
+		}
+	}()
+
+	udp, err := net.ListenPacket("udp4", l.Addr().String())
+	if err != nil {
+		t.Skipf("UDP port unavailable: %s", err)
+	}
+	defer udp.Close()
+
+	var received []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := udp.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			received = append(received, decrypt(buf[:n]))
+			udp.WriteTo(encrypt(`{"system":{"get_sysinfo":{"err_code":0}}}`), addr)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	// the rule may have been created, it must not be sent again
+	addRule := `{"schedule":{"add_rule":{}}}`
+	if _, err := execTCPWithUDPFallback(ctx, l.Addr().String(), addRule); err == nil {
+		t.Error("expecting an error")
+	}
+
+	if _, err := execTCPWithUDPFallback(ctx, l.Addr().String(), GET_INFO); err != nil {
+		t.Error(err)
+	}
+
+	udp.Close()
+	<-done
+	if len(received) != 1 || received[0] != GET_INFO {
+		t.Errorf("expecting only %s over UDP; got %v", GET_INFO, received)
+	}
+}
+
+func TestExecUDPCancel(t *testing.T) {
+	// a device that never answers
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = execUDP(ctx, conn.LocalAddr().String(), GET_INFO)
+	if err != context.Canceled {
+		t.Errorf("expecting %v; got %v", context.Canceled, err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancellation took %s", elapsed)
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	f := &fakeTransport{replies: map[string]string{
+		GET_INFO: `{"system":{"get_sysinfo":{"alias":"Plug1","relay_state":1,"err_code":0}}}`,
+	}}
+
+	plug := NewHS100("10.0.0.1", time.Second, WithTransport(f))
+	info, err := plug.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Alias != "Plug1" || !info.IsOn() {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	if len(f.sent) != 1 || f.sent[0] != GET_INFO {
+		t.Errorf("expecting %s to be sent; got %v", GET_INFO, f.sent)
+	}
+
+	f.err = errors.New("unreachable")
+	if err := plug.TurnOn(); !errors.Is(err, f.err) {
+		t.Errorf("expecting %v; got %v", f.err, err)
+	}
+}